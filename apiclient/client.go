@@ -0,0 +1,89 @@
+// Package apiclient implements the JSON over HTTP calls shared by the Tyk Dashboard and Tyk Enterprise
+// Developer Portal clients.
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Client sends JSON requests to an API reachable at a base URL.
+type Client struct {
+	// service names the API in errors, e.g. "dashboard".
+	service    string
+	url        string
+	httpClient *http.Client
+}
+
+// New returns a Client sending requests to the API named service, reachable at url. If httpClient is nil,
+// http.DefaultClient is used.
+func New(service, url string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		service:    service,
+		url:        strings.TrimSuffix(url, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// URL returns the base URL of the API.
+func (c *Client) URL() string {
+	return c.url
+}
+
+// Do sends a request to the API, authenticated with auth in the authHeader header unless authHeader or
+// auth is empty. If in is not nil, it is encoded as the JSON request body. If out is not nil, a successful
+// response body is decoded into it. Any non-2xx response is returned as *Error.
+func (c *Client) Do(ctx context.Context, method, path, authHeader, auth string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		reqBody, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body for %s %s, err: %v", method, path, err)
+		}
+		body = bytes.NewReader(reqBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, body)
+	if err != nil {
+		return err
+	}
+
+	if authHeader != "" && auth != "" {
+		req.Header.Set(authHeader, auth)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body of %s %s, err: %v", method, path, err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return newError(c.service, method, path, res.StatusCode, resBody)
+	}
+
+	if out == nil || len(resBody) == 0 {
+		return nil
+	}
+
+	if err = json.Unmarshal(resBody, out); err != nil {
+		return fmt.Errorf("failed to decode response body of %s %s, err: %v", method, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("admin-auth") != "secret" || string(body) != `{"name":"tyk"}` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		fmt.Fprint(w, `{"id":"1"}`)
+	}))
+	defer srv.Close()
+
+	var res struct {
+		ID string `json:"id"`
+	}
+	err := New("dashboard", srv.URL+"/", nil).Do(context.Background(), http.MethodPost, "/api/orgs", "admin-auth",
+		"secret", map[string]string{"name": "tyk"}, &res)
+	if err != nil || res.ID != "1" {
+		t.Errorf("expected the response to be decoded, got %+v and error %v", res, err)
+	}
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		service string
+		want    string
+		code    int
+	}{
+		{
+			name:    "general response",
+			status:  http.StatusForbidden,
+			body:    `{"Status":"Error","Message":"Not authorised"}`,
+			service: "dashboard",
+			want:    "dashboard responded to GET /api/users with status 403: Not authorised",
+			code:    http.StatusForbidden,
+		},
+		{
+			name:    "raw body",
+			status:  http.StatusBadRequest,
+			body:    "invalid request\n",
+			service: "dashboard",
+			want:    "dashboard responded to GET /api/users with status 400: invalid request",
+			code:    http.StatusBadRequest,
+		},
+		{
+			name:    "empty body",
+			status:  http.StatusBadGateway,
+			service: "dashboard",
+			want:    "dashboard responded to GET /api/users with status 502: Bad Gateway",
+			code:    http.StatusBadGateway,
+		},
+		{
+			name:    "other service",
+			status:  http.StatusNotFound,
+			service: "portal",
+			want:    "portal responded to GET /api/users with status 404: Not Found",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			defer srv.Close()
+
+			err := New(tc.service, srv.URL, nil).Do(context.Background(), http.MethodGet, "/api/users", "", "", nil, nil)
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("expected error %q, got %v", tc.want, err)
+			}
+
+			if code := StatusCode(err, "dashboard"); code != tc.code {
+				t.Errorf("expected dashboard status code %d, got %d", tc.code, code)
+			}
+		})
+	}
+}
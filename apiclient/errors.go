@@ -0,0 +1,69 @@
+package apiclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error is returned for every response with a non-2xx status code.
+type Error struct {
+	// Service names the API that responded, e.g. "dashboard".
+	Service    string
+	Method     string
+	Path       string
+	StatusCode int
+	// Status and Message are decoded from the body of the response, if the API answered with the general
+	// response format of Tyk.
+	Status  string
+	Message string
+	// Body holds the raw body of the response.
+	Body string
+}
+
+func newError(service, method, path string, statusCode int, body []byte) *Error {
+	e := &Error{
+		Service:    service,
+		Method:     method,
+		Path:       path,
+		StatusCode: statusCode,
+		Body:       strings.TrimSpace(string(body)),
+	}
+
+	// Meta is not a string for every endpoint, so only Status and Message are decoded here.
+	var res struct {
+		Status  string `json:"Status"`
+		Message string `json:"Message"`
+	}
+	if err := json.Unmarshal(body, &res); err == nil {
+		e.Status = res.Status
+		e.Message = res.Message
+	}
+
+	return e
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Body
+	}
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+
+	return fmt.Sprintf("%s responded to %s %s with status %d: %s", e.Service, e.Method, e.Path, e.StatusCode, msg)
+}
+
+// StatusCode returns the HTTP status code of the response of service that caused err, or 0 if err was not
+// caused by a non-2xx response of service.
+func StatusCode(err error, service string) int {
+	var e *Error
+	if errors.As(err, &e) && e.Service == service {
+		return e.StatusCode
+	}
+
+	return 0
+}
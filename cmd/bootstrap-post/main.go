@@ -1,76 +1,136 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
+	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/dashboard"
 	"tyk/tyk/bootstrap/data"
 	"tyk/tyk/bootstrap/helpers"
+	"tyk/tyk/bootstrap/k8s"
+	"tyk/tyk/bootstrap/license"
+	"tyk/tyk/bootstrap/portal"
 	"tyk/tyk/bootstrap/readiness"
+	"tyk/tyk/bootstrap/retry"
 )
 
 func main() {
+	k8s.BindFlags(flag.CommandLine)
+	data.BindFlags(flag.CommandLine)
+	flag.Parse()
+
 	err := data.InitAppDataPostInstall()
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	err = readiness.CheckIfRequiredDeploymentsAreReady()
+	ctx := context.Background()
+
+	err = helpers.CheckPermissions(ctx)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	tp := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: data.AppConfig.DashboardInsecureSkipVerify},
+	// The license is validated by the pre installation hook, its claims are only read here to warn about
+	// its expiry.
+	if data.AppConfig.DashBoardLicense != "" {
+		info, err := license.ParseUnverified(data.AppConfig.DashBoardLicense)
+		if info == nil {
+			fmt.Printf("[WARNING] Skipping the license expiry check, %v\n", err)
+		} else if err = license.CheckExpiry(ctx, info); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 	}
-	client := http.Client{Transport: tp}
 
-	fmt.Println("Started creating dashboard org")
-	err = helpers.CheckForExistingOrganisation(client)
+	err = readiness.CheckIfRequiredDeploymentsAreReady()
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	fmt.Println("Finished creating dashboard org")
 
-	fmt.Println("Generating dashboard credentials")
-	err = helpers.GenerateDashboardCredentials(client)
+	tp := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: data.AppConfig.DashboardInsecureSkipVerify,
+			RootCAs:            data.AppConfig.DashboardRootCAs,
+		},
+	}
+	client := dashboard.NewClient(data.AppConfig.DashboardUrl, &http.Client{
+		Transport: &retry.Transport{Base: tp, Policy: data.AppConfig.RetryPolicy},
+	})
+
+	fmt.Println("Waiting for dashboard API to be ready")
+	err = readiness.WaitForDashboard(ctx, client)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	fmt.Println("Finished generating dashboard credentials")
 
-	fmt.Println("Started bootstrapping operator secret")
-	if data.AppConfig.OperatorSecretEnabled {
-		err = helpers.BootstrapTykOperatorSecret()
+	// Organisations are bootstrapped in order. A failed organisation does not prevent the next ones from
+	// being bootstrapped, but fails the job.
+	var failed []string
+	var portalOrgs []*data.Organisation
+	for _, org := range data.AppConfig.Organisations {
+		err = helpers.BootstrapOrganisation(ctx, client, org)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			fmt.Printf("[ERROR] Failed to bootstrap organisation %v, err: %v\n", org, err)
+			failed = append(failed, org.String())
+			continue
+		}
+
+		if org.BootstrapPortal {
+			portalOrgs = append(portalOrgs, org)
 		}
 	}
-	fmt.Println("Finished bootstrapping operator secret")
 
-	fmt.Println("Started bootstrapping portal secret")
-	if data.AppConfig.DeveloperPortalSecretEnabled {
-		err = helpers.BootstrapTykPortalSecret()
+	switch {
+	case len(portalOrgs) == 0:
+	case data.AppConfig.PortalMode == constants.PortalModeEnterprise:
+		// The portal is verified with its own TLS settings, as it may be served with another certificate than
+		// the Dashboard.
+		portalTp := &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: data.AppConfig.EnterprisePortalInsecureSkipVerify,
+				RootCAs:            data.AppConfig.EnterprisePortalRootCAs,
+			},
+		}
+		portalClient := portal.NewClient(data.AppConfig.EnterprisePortalUrl, &http.Client{
+			Transport: &retry.Transport{Base: portalTp, Policy: data.AppConfig.RetryPolicy},
+		})
+
+		fmt.Println("Waiting for enterprise portal to be ready")
+		err = readiness.WaitForEnterprisePortal(ctx, portalClient)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
-	}
 
-	fmt.Println("Started bootstrapping portal with requests to dashboard")
-	if data.AppConfig.BootstrapPortal {
-		err = helpers.BoostrapPortal(client)
+		fmt.Println("Started bootstrapping enterprise portal")
+		err = helpers.BootstrapEnterprisePortal(ctx, portalClient, portalOrgs)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Println("Finished bootstrapping enterprise portal")
+	default:
+		// restarting the dashboard to apply the new portal cnames
+		err = helpers.RestartDashboard(ctx)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
 	}
-	fmt.Println("Finished bootstrapping portal")
 
+	if len(failed) > 0 {
+		fmt.Printf("Failed to bootstrap %d of %d organisations: %v\n",
+			len(failed), len(data.AppConfig.Organisations), strings.Join(failed, ", "))
+		os.Exit(1)
+	}
 }
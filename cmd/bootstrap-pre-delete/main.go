@@ -1,13 +1,19 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"tyk/tyk/bootstrap/data"
+	"tyk/tyk/bootstrap/k8s"
 	"tyk/tyk/bootstrap/predelete"
 )
 
 func main() {
+	k8s.BindFlags(flag.CommandLine)
+	data.BindFlags(flag.CommandLine)
+	flag.Parse()
+
 	err := data.InitAppDataPreDelete()
 	if err != nil {
 		fmt.Println(err)
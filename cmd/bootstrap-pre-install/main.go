@@ -1,13 +1,25 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"tyk/tyk/bootstrap/data"
+	"tyk/tyk/bootstrap/k8s"
 	"tyk/tyk/bootstrap/preinstallation"
 )
 
 func main() {
-	err := preinstallation.PreHookInstall()
+	k8s.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	err := data.InitAppDataPreInstall()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	err = preinstallation.PreHookInstall()
 	if err != nil {
 		fmt.Printf("Failed to run pre-hook job, err: %v", err)
 		os.Exit(1)
@@ -1,28 +1,103 @@
 package constants
 
 const (
-	OperatorSecretEnabledEnvVar        = "OPERATOR_SECRET_ENABLED"
-	DeveloperPortalSecretEnabledEnvVar = "DEVELOPER_PORTAL_SECRET_ENABLED"
-	BootstrapPortalEnvVar              = "BOOTSTRAP_PORTAL"
-	TykDashboardDeployEnvVar           = "TYK_DASHBOARD_DEPLOY"
-	OperatorSecretNameEnvVar           = "OPERATOR_SECRET_NAME"
-	DeveloperPortalSecretNameEnvVar    = "DEVELOPER_PORTAL_SECRET_NAME"
-	TykAdminFirstNameEnvVar            = "TYK_ADMIN_FIRST_NAME"
-	TykAdminLastNameEnvVar             = "TYK_ADMIN_LAST_NAME"
-	TykAdminEmailEnvVar                = "TYK_ADMIN_EMAIL"
-	TykAdminPasswordEnvVar             = "TYK_ADMIN_PASSWORD"
-	TykPodNamespaceEnvVar              = "TYK_POD_NAMESPACE"
-	TykDashboardProtoEnvVar            = "TYK_DASHBOARD_PROTO"
-	TykDashboardInsecureSkipVerify     = "TYK_DASHBOARD_INSECURE_SKIP_VERIFY"
-	TykDashboardLicenseEnvVarName      = "TYK_DB_LICENSEKEY"
-	TykDbLicensekeyEnvVar              = "TYK_DB_LICENSEKEY"
-	TykAdminSecretEnvVar               = "TYK_ADMIN_SECRET"
-	DashboardEnabledEnvVar             = "DASHBOARD_ENABLED"
-	TykOrgNameEnvVar                   = "TYK_ORG_NAME"
-	TykOrgCnameEnvVar                  = "TYK_ORG_CNAME"
+	OperatorSecretEnabledEnvVar                  = "OPERATOR_SECRET_ENABLED"
+	DeveloperPortalSecretEnabledEnvVar           = "DEVELOPER_PORTAL_SECRET_ENABLED"
+	PortalPagesDirEnvVar                         = "PORTAL_PAGES_DIR"
+	BootstrapPortalEnvVar                        = "BOOTSTRAP_PORTAL"
+	TykDashboardDeployEnvVar                     = "TYK_DASHBOARD_DEPLOY"
+	OperatorSecretNameEnvVar                     = "OPERATOR_SECRET_NAME"
+	DeveloperPortalSecretNameEnvVar              = "DEVELOPER_PORTAL_SECRET_NAME"
+	OperatorSecretNamespacesEnvVar               = "OPERATOR_SECRET_NAMESPACES"
+	OperatorSecretNamespaceSelectorEnvVar        = "OPERATOR_SECRET_NAMESPACE_SELECTOR"
+	DeveloperPortalSecretNamespacesEnvVar        = "DEVELOPER_PORTAL_SECRET_NAMESPACES"
+	DeveloperPortalSecretNamespaceSelectorEnvVar = "DEVELOPER_PORTAL_SECRET_NAMESPACE_SELECTOR"
+	TykAdminFirstNameEnvVar                      = "TYK_ADMIN_FIRST_NAME"
+	TykAdminLastNameEnvVar                       = "TYK_ADMIN_LAST_NAME"
+	TykAdminEmailEnvVar                          = "TYK_ADMIN_EMAIL"
+	TykAdminPasswordEnvVar                       = "TYK_ADMIN_PASSWORD"
+	TykAdminPasswordGenerateEnvVar               = "TYK_ADMIN_PASSWORD_GENERATE"
+	AdminCredentialsSecretNameEnvVar             = "TYK_ADMIN_CREDENTIALS_SECRET_NAME"
+	TykPodNamespaceEnvVar                        = "TYK_POD_NAMESPACE"
+	TykDashboardProtoEnvVar                      = "TYK_DASHBOARD_PROTO"
+	TykDashboardInsecureSkipVerify               = "TYK_DASHBOARD_INSECURE_SKIP_VERIFY"
+	TykDashboardCACertEnvVar                     = "TYK_DASHBOARD_CA_CERT"
+	TykDashboardUrlEnvVar                        = "TYK_DASHBOARD_URL"
+	BootstrapConfigFileEnvVar                    = "BOOTSTRAP_CONFIG_FILE"
+	TykDashboardLicenseEnvVarName                = "TYK_DB_LICENSEKEY"
+	TykDbLicensekeyEnvVar                        = "TYK_DB_LICENSEKEY"
+	TykLicensePublicKeyEnvVar                    = "TYK_LICENSE_PUBLIC_KEY"
+	TykLicensePublicKeyFileEnvVar                = "TYK_LICENSE_PUBLIC_KEY_FILE"
+	TykAdminSecretEnvVar                         = "TYK_ADMIN_SECRET"
+	DashboardEnabledEnvVar                       = "DASHBOARD_ENABLED"
+	TykOrgNameEnvVar                             = "TYK_ORG_NAME"
+	TykOrgCnameEnvVar                            = "TYK_ORG_CNAME"
+	AdoptExistingOrgEnvVar                       = "ADOPT_EXISTING_ORG"
+	TykGatewayReplicasEnvVar                     = "TYK_GATEWAY_REPLICAS"
+	TykMdcbEnabledEnvVar                         = "TYK_MDCB_ENABLED"
+	LicenseEntitlementsStrictEnvVar              = "LICENSE_ENTITLEMENTS_STRICT"
+	LicenseExpiryWarningThresholdEnvVar          = "LICENSE_EXPIRY_WARNING_THRESHOLD"
+	LicenseExpiryStrictEnvVar                    = "LICENSE_EXPIRY_STRICT"
+	ReadinessPodSelectorsEnvVar                  = "READINESS_POD_SELECTORS"
+	ReadinessDeploymentSelectorsEnvVar           = "READINESS_DEPLOYMENT_SELECTORS"
+	ReadinessDeploymentsEnvVar                   = "READINESS_DEPLOYMENTS"
+	ReadinessStatefulSetsEnvVar                  = "READINESS_STATEFULSETS"
+	ReadinessTimeoutEnvVar                       = "READINESS_TIMEOUT"
+	DashboardHealthTimeoutEnvVar                 = "DASHBOARD_HEALTH_TIMEOUT"
+	DashboardRolloutTimeoutEnvVar                = "DASHBOARD_ROLLOUT_TIMEOUT"
+	RetryMaxAttemptsEnvVar                       = "RETRY_MAX_ATTEMPTS"
+	RetryInitialBackoffEnvVar                    = "RETRY_INITIAL_BACKOFF"
+	RetryMaxBackoffEnvVar                        = "RETRY_MAX_BACKOFF"
+	RetryJitterEnvVar                            = "RETRY_JITTER"
+	RetryStatusCodesEnvVar                       = "RETRY_STATUS_CODES"
+	RequestTimeoutEnvVar                         = "REQUEST_TIMEOUT"
+	OperatorContextEnabledEnvVar                 = "OPERATOR_CONTEXT_ENABLED"
+	OperatorContextNameEnvVar                    = "OPERATOR_CONTEXT_NAME"
+	OperatorContextNamespaceEnvVar               = "OPERATOR_CONTEXT_NAMESPACE"
+	HelmReleaseNameEnvVar                        = "HELM_RELEASE_NAME"
+	HelmReleaseNamespaceEnvVar                   = "HELM_RELEASE_NAMESPACE"
+	SecretOwnerReferencesEnvVar                  = "SECRET_OWNER_REFERENCES_ENABLED"
+	PortalModeEnvVar                             = "PORTAL_MODE"
+	EnterprisePortalUrlEnvVar                    = "ENTERPRISE_PORTAL_URL"
+	EnterprisePortalHealthTimeoutEnvVar          = "ENTERPRISE_PORTAL_HEALTH_TIMEOUT"
+	EnterprisePortalInsecureSkipVerifyEnvVar     = "ENTERPRISE_PORTAL_INSECURE_SKIP_VERIFY"
+	EnterprisePortalCACertEnvVar                 = "ENTERPRISE_PORTAL_CA_CERT"
+	EnterprisePortalAdminFirstNameEnvVar         = "ENTERPRISE_PORTAL_ADMIN_FIRST_NAME"
+	EnterprisePortalAdminLastNameEnvVar          = "ENTERPRISE_PORTAL_ADMIN_LAST_NAME"
+	EnterprisePortalAdminEmailEnvVar             = "ENTERPRISE_PORTAL_ADMIN_EMAIL"
+	EnterprisePortalAdminPasswordEnvVar          = "ENTERPRISE_PORTAL_ADMIN_PASSWORD"
+	EnterprisePortalCredentialsSecretNameEnvVar  = "ENTERPRISE_PORTAL_CREDENTIALS_SECRET_NAME"
+
+	// PortalModeClassic bootstraps the classic portal embedded in the Dashboard, and PortalModeEnterprise the
+	// standalone Tyk Enterprise Developer Portal.
+	PortalModeClassic    = "classic"
+	PortalModeEnterprise = "enterprise"
 
 	TykBootstrapLabel                = "tyk.tyk.io/k8s-bootstrap"
 	TykBootstrapPreDeleteLabel       = "tyk-k8s-bootstrap-pre-delete"
 	TykBootstrapDashboardDeployLabel = "tyk-dashboard"
 	TykBootstrapDashboardSvcLabel    = "tyk-dashboard"
+	TykBootstrapOperatorSecretLabel  = "tyk-operator-secret"
+	TykBootstrapPortalSecretLabel    = "tyk-portal-secret"
+	TykBootstrapOperatorContextLabel = "tyk-operator-context"
+	// TykBootstrapAdminCredentialsLabel labels the Secrets holding the credentials of Dashboard users.
+	TykBootstrapAdminCredentialsLabel = "tyk-admin-credentials"
+	// TykBootstrapEnterprisePortalCredentialsLabel labels the Secret of the Enterprise Developer Portal admin.
+	TykBootstrapEnterprisePortalCredentialsLabel = "tyk-enterprise-portal-credentials"
+
+	AppNameLabel        = "app.kubernetes.io/name"
+	AppInstanceLabel    = "app.kubernetes.io/instance"
+	AppComponentLabel   = "app.kubernetes.io/component"
+	AppPartOfLabel      = "app.kubernetes.io/part-of"
+	AppManagedByLabel   = "app.kubernetes.io/managed-by"
+	TykBootstrapAppName = "tyk-k8s-bootstrap"
+
+	HelmReleaseNameAnnotation      = "meta.helm.sh/release-name"
+	HelmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+
+	TykLicenseExpiryWarningAnnotation = "tyk.tyk.io/license-expiry-warning"
+
+	// RedisPodSelector selects the Pods of the Redis charts deployed along with Tyk, which must be ready before
+	// bootstrapping unless other Pods are selected.
+	RedisPodSelector = "app.kubernetes.io/name in (redis,redis-cluster)"
 )
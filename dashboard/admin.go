@@ -0,0 +1,54 @@
+package dashboard
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	adminOrganisationsEndpoint = "/admin/organisations"
+	adminUsersEndpoint         = "/admin/users"
+)
+
+// ListOrganisations returns all organisations known by the Dashboard, reading every page of the list.
+func (a *AdminClient) ListOrganisations(ctx context.Context) ([]Organisation, error) {
+	var orgs []Organisation
+	for page := 1; ; page++ {
+		res := OrganisationsResponse{}
+		err := a.do(ctx, http.MethodGet, pagePath(adminOrganisationsEndpoint, page), nil, &res)
+		if err != nil {
+			return nil, err
+		}
+
+		orgs = append(orgs, res.Organisations...)
+		if page >= res.Pages || len(res.Organisations) == 0 {
+			return orgs, nil
+		}
+	}
+}
+
+// CreateOrganisation creates an organisation and returns its ID.
+func (a *AdminClient) CreateOrganisation(ctx context.Context, req CreateOrganisationRequest) (string, error) {
+	res := GeneralResponse{}
+	if err := a.do(ctx, http.MethodPost, adminOrganisationsEndpoint, req, &res); err != nil {
+		return "", err
+	}
+
+	return res.Meta, nil
+}
+
+// CreateUser creates a user in the organisation given in req. The returned User has its AccessKey set.
+func (a *AdminClient) CreateUser(ctx context.Context, req CreateUserRequest) (User, error) {
+	res := CreateUserResponse{}
+	if err := a.do(ctx, http.MethodPost, adminUsersEndpoint, req, &res); err != nil {
+		return User{}, err
+	}
+
+	user := res.Meta
+	// The access key of the created user is reported in the response message.
+	if res.Message != "" {
+		user.AccessKey = res.Message
+	}
+
+	return user, nil
+}
@@ -0,0 +1,87 @@
+// Package dashboard implements a small typed client for the Tyk Dashboard API.
+//
+// The Client itself does not carry any credentials. Calls against the Dashboard Admin API are made
+// through an AdminClient, obtained via Client.Admin, which authenticates with the Dashboard admin
+// secret. Calls made on behalf of a Dashboard user are made through a UserClient, obtained via
+// Client.User, which authenticates with the user's API access key.
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"tyk/tyk/bootstrap/apiclient"
+)
+
+const (
+	adminAuthHeader = "admin-auth"
+	userAuthHeader  = "Authorization"
+
+	helloEndpoint = "/hello"
+)
+
+// Client is a Tyk Dashboard API client.
+type Client struct {
+	api *apiclient.Client
+}
+
+// NewClient returns a Client sending requests to the Dashboard reachable at url. If httpClient is nil,
+// http.DefaultClient is used.
+func NewClient(url string, httpClient *http.Client) *Client {
+	return &Client{api: apiclient.New(service, url, httpClient)}
+}
+
+// URL returns the base URL of the Dashboard.
+func (c *Client) URL() string {
+	return c.api.URL()
+}
+
+// Hello calls the unauthenticated health check endpoint of the Dashboard.
+func (c *Client) Hello(ctx context.Context) (HelloResponse, error) {
+	res := HelloResponse{}
+	err := c.do(ctx, http.MethodGet, helloEndpoint, "", "", nil, &res)
+
+	return res, err
+}
+
+// Admin returns a client authenticating against the Dashboard Admin API with the given admin secret.
+func (c *Client) Admin(secret string) *AdminClient {
+	return &AdminClient{client: c, secret: secret}
+}
+
+// User returns a client authenticating against the Dashboard API with the given user access key.
+func (c *Client) User(auth string) *UserClient {
+	return &UserClient{client: c, auth: auth}
+}
+
+// AdminClient sends requests authenticated with the Dashboard admin secret.
+type AdminClient struct {
+	client *Client
+	secret string
+}
+
+func (a *AdminClient) do(ctx context.Context, method, path string, in, out interface{}) error {
+	return a.client.do(ctx, method, path, adminAuthHeader, a.secret, in, out)
+}
+
+// UserClient sends requests authenticated with a Dashboard user's access key.
+type UserClient struct {
+	client *Client
+	auth   string
+}
+
+func (u *UserClient) do(ctx context.Context, method, path string, in, out interface{}) error {
+	return u.client.do(ctx, method, path, userAuthHeader, u.auth, in, out)
+}
+
+// pagePath returns path with the query parameter selecting the given page, starting at 1, of a paginated
+// list. The response of such lists holds the number of pages.
+func pagePath(path string, page int) string {
+	return fmt.Sprintf("%s?p=%d", path, page)
+}
+
+// do sends a request to the Dashboard, authenticated with auth in the authHeader header unless authHeader
+// is empty, see apiclient.Client.Do.
+func (c *Client) do(ctx context.Context, method, path, authHeader, auth string, in, out interface{}) error {
+	return c.api.Do(ctx, method, path, authHeader, auth, in, out)
+}
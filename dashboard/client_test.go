@@ -0,0 +1,53 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListOrganisationsReadsEveryPage(t *testing.T) {
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("p")
+		pages = append(pages, page)
+		fmt.Fprintf(w, `{"organisations": [{"id": "org-%s"}], "pages": 3}`, page)
+	}))
+	defer srv.Close()
+
+	orgs, err := NewClient(srv.URL, nil).Admin("secret").ListOrganisations(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(orgs) != 3 || orgs[0].ID != "org-1" || orgs[2].ID != "org-3" {
+		t.Errorf("unexpected organisations %+v", orgs)
+	}
+	if fmt.Sprint(pages) != "[1 2 3]" {
+		t.Errorf("unexpected pages requested %v", pages)
+	}
+}
+
+func TestListUsersStopsAtEmptyPage(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("p") == "1" {
+			fmt.Fprint(w, `{"users": [{"id": "u1"}], "pages": 5}`)
+			return
+		}
+		fmt.Fprint(w, `{"users": [], "pages": 5}`)
+	}))
+	defer srv.Close()
+
+	users, err := NewClient(srv.URL, nil).User("key").ListUsers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(users) != 1 || requests != 2 {
+		t.Errorf("got %d users in %d requests, want 1 user in 2 requests", len(users), requests)
+	}
+}
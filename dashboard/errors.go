@@ -0,0 +1,15 @@
+package dashboard
+
+import "tyk/tyk/bootstrap/apiclient"
+
+// service names the Dashboard in errors.
+const service = "dashboard"
+
+// Error is returned for every Dashboard response with a non-2xx status code.
+type Error = apiclient.Error
+
+// StatusCode returns the HTTP status code of the Dashboard response that caused err, or 0 if err was not
+// caused by a non-2xx Dashboard response.
+func StatusCode(err error) int {
+	return apiclient.StatusCode(err, service)
+}
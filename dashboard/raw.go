@@ -0,0 +1,57 @@
+package dashboard
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// RawObject is a Dashboard object kept as decoded JSON, for objects that are read, partially changed and
+// written back. Unlike a struct, it preserves the fields this client does not model, so writing it back
+// does not reset them. Numbers are kept as json.Number, so that they are written back unchanged.
+type RawObject map[string]interface{}
+
+func (o *RawObject) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var m map[string]interface{}
+	if err := decoder.Decode(&m); err != nil {
+		return err
+	}
+	*o = m
+
+	return nil
+}
+
+// String returns the string value of key, or "" if it is not set or not a string.
+func (o RawObject) String(key string) string {
+	s, _ := o[key].(string)
+	return s
+}
+
+// Merge sets the values of src in o. Nested objects are merged recursively, so that their fields src
+// leaves unset are kept, while any other value, including arrays, replaces the value in o.
+func (o RawObject) Merge(src RawObject) {
+	for key, value := range src {
+		srcObject, srcIsObject := asObject(value)
+		dstObject, dstIsObject := asObject(o[key])
+		if srcIsObject && dstIsObject {
+			dstObject.Merge(srcObject)
+			o[key] = dstObject
+			continue
+		}
+
+		o[key] = value
+	}
+}
+
+func asObject(v interface{}) (RawObject, bool) {
+	switch o := v.(type) {
+	case RawObject:
+		return o, o != nil
+	case map[string]interface{}:
+		return o, o != nil
+	default:
+		return nil, false
+	}
+}
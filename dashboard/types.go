@@ -0,0 +1,162 @@
+package dashboard
+
+import "time"
+
+// GeneralResponse is the response format used by most Dashboard API endpoints.
+type GeneralResponse struct {
+	Status  string `json:"Status"`
+	Message string `json:"Message"`
+	Meta    string `json:"Meta"`
+}
+
+type HelloResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Version string `json:"version"`
+}
+
+type Organisation struct {
+	ID           string `json:"id"`
+	OwnerName    string `json:"owner_name"`
+	OwnerSlug    string `json:"owner_slug"`
+	CnameEnabled bool   `json:"cname_enabled"`
+	Cname        string `json:"cname"`
+}
+
+type OrganisationsResponse struct {
+	Organisations []Organisation `json:"organisations"`
+	Pages         int            `json:"pages"`
+}
+
+type CreateOrganisationRequest struct {
+	OwnerName    string `json:"owner_name"`
+	CnameEnabled bool   `json:"cname_enabled"`
+	Cname        string `json:"cname"`
+}
+
+type User struct {
+	ID              string            `json:"id"`
+	FirstName       string            `json:"first_name"`
+	LastName        string            `json:"last_name"`
+	EmailAddress    string            `json:"email_address"`
+	OrgID           string            `json:"org_id"`
+	Active          bool              `json:"active"`
+	AccessKey       string            `json:"access_key"`
+	UserPermissions map[string]string `json:"user_permissions"`
+	GroupID         string            `json:"group_id"`
+	PasswordMaxDays int               `json:"password_max_days"`
+	PasswordUpdated time.Time         `json:"password_updated"`
+	CreatedAt       time.Time         `json:"created_at"`
+}
+
+type CreateUserRequest struct {
+	OrgID           string            `json:"org_id"`
+	FirstName       string            `json:"first_name"`
+	LastName        string            `json:"last_name"`
+	EmailAddress    string            `json:"email_address"`
+	Active          bool              `json:"active"`
+	UserPermissions map[string]string `json:"user_permissions"`
+	GroupID         string            `json:"group_id,omitempty"`
+}
+
+// UpdateUserRequest updates the profile, permissions and user group of a user.
+type UpdateUserRequest struct {
+	FirstName       string            `json:"first_name"`
+	LastName        string            `json:"last_name"`
+	EmailAddress    string            `json:"email_address"`
+	Active          bool              `json:"active"`
+	UserPermissions map[string]string `json:"user_permissions"`
+	GroupID         string            `json:"group_id"`
+}
+
+type CreateUserResponse struct {
+	Status  string `json:"Status"`
+	Message string `json:"Message"`
+	Meta    User   `json:"Meta"`
+}
+
+type ResetPasswordRequest struct {
+	NewPassword     string            `json:"new_password"`
+	UserPermissions map[string]string `json:"user_permissions"`
+}
+
+type CnameRequest struct {
+	Cname string `json:"cname"`
+}
+
+type InitCatalogueRequest struct {
+	OrgID string `json:"org_id"`
+}
+
+// PortalPage is a page of the classic developer portal. The keys of Fields depend on the template
+// used by the page.
+type PortalPage struct {
+	ID           string            `json:"id,omitempty"`
+	IsHomepage   bool              `json:"is_homepage"`
+	TemplateName string            `json:"template_name"`
+	Title        string            `json:"title"`
+	Slug         string            `json:"slug"`
+	Fields       map[string]string `json:"fields"`
+}
+
+// CreateDocumentationRequest uploads the base64 encoded documentation of a catalogue API.
+type CreateDocumentationRequest struct {
+	APIID         string `json:"api_id"`
+	DocType       string `json:"doc_type"`
+	Documentation string `json:"documentation"`
+}
+
+// Documentation is the uploaded documentation of a catalogue API.
+type Documentation struct {
+	ID            string `json:"id"`
+	APIID         string `json:"api_id"`
+	DocType       string `json:"doc_type"`
+	Documentation string `json:"documentation"`
+}
+
+// PortalCSS is the custom stylesheet of the portal pages.
+type PortalCSS struct {
+	OrgID   string `json:"org_id"`
+	PageCSS string `json:"page_css"`
+}
+
+// PortalJS is the custom script of the portal pages.
+type PortalJS struct {
+	OrgID  string `json:"org_id"`
+	PageJS string `json:"page_js"`
+}
+
+// PortalMenus are the menus of the portal, by name.
+type PortalMenus struct {
+	OrgID string                      `json:"org_id"`
+	Menus map[string][]PortalMenuItem `json:"menus"`
+}
+
+type PortalMenuItem struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+type PortalPagesResponse struct {
+	Pages []PortalPage `json:"Data"`
+}
+
+type UsersResponse struct {
+	Users []User `json:"users"`
+	Pages int    `json:"pages"`
+}
+
+// UserGroup is a Dashboard user group. Users of a group have the permissions of the group.
+type UserGroup struct {
+	ID              string            `json:"id,omitempty"`
+	OrgID           string            `json:"org_id,omitempty"`
+	Name            string            `json:"name"`
+	Description     string            `json:"description"`
+	Active          bool              `json:"active"`
+	UserPermissions map[string]string `json:"user_permissions"`
+}
+
+type UserGroupsResponse struct {
+	Groups []UserGroup `json:"groups"`
+	Pages  int         `json:"pages"`
+}
@@ -0,0 +1,196 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const (
+	apiUsersEndpoint               = "/api/users"
+	apiUserEndpoint                = "/api/users/%s"
+	apiUsersActionsResetEndpoint   = "/api/users/%s/actions/reset"
+	apiUserGroupsEndpoint          = "/api/usergroups"
+	apiUserGroupEndpoint           = "/api/usergroups/%s"
+	apiPortalCatalogueEndpoint     = "/api/portal/catalogue"
+	apiPortalDocumentationEndpoint = "/api/portal/documentation"
+	apiPortalDocumentEndpoint      = "/api/portal/documentation/%s"
+	apiPortalPagesEndpoint         = "/api/portal/pages"
+	apiPortalPageEndpoint          = "/api/portal/pages/%s"
+	apiPortalConfigurationEndpoint = "/api/portal/configuration"
+	apiPortalConfigEndpoint        = "/api/portal/configuration/%s"
+	apiPortalCSSEndpoint           = "/api/portal/css"
+	apiPortalJSEndpoint            = "/api/portal/js"
+	apiPortalMenusEndpoint         = "/api/portal/menus"
+	apiPortalCnameEndpoint         = "/api/portal/cname"
+)
+
+// ListUsers returns the users of the organisation the authenticated user belongs to, reading every page of
+// the list.
+func (u *UserClient) ListUsers(ctx context.Context) ([]User, error) {
+	var users []User
+	for page := 1; ; page++ {
+		res := UsersResponse{}
+		if err := u.do(ctx, http.MethodGet, pagePath(apiUsersEndpoint, page), nil, &res); err != nil {
+			return nil, err
+		}
+
+		users = append(users, res.Users...)
+		if page >= res.Pages || len(res.Users) == 0 {
+			return users, nil
+		}
+	}
+}
+
+// UpdateUser updates the profile, permissions and user group of the user with the given ID.
+func (u *UserClient) UpdateUser(ctx context.Context, userID string, req UpdateUserRequest) error {
+	return u.do(ctx, http.MethodPut, fmt.Sprintf(apiUserEndpoint, userID), req, nil)
+}
+
+// ListUserGroups returns the user groups of the organisation the authenticated user belongs to.
+func (u *UserClient) ListUserGroups(ctx context.Context) ([]UserGroup, error) {
+	res := UserGroupsResponse{}
+	if err := u.do(ctx, http.MethodGet, apiUserGroupsEndpoint, nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Groups, nil
+}
+
+// CreateUserGroup creates a user group and returns its ID.
+func (u *UserClient) CreateUserGroup(ctx context.Context, group UserGroup) (string, error) {
+	res := GeneralResponse{}
+	if err := u.do(ctx, http.MethodPost, apiUserGroupsEndpoint, group, &res); err != nil {
+		return "", err
+	}
+
+	return res.Meta, nil
+}
+
+// UpdateUserGroup updates the user group with the given ID.
+func (u *UserClient) UpdateUserGroup(ctx context.Context, groupID string, group UserGroup) error {
+	return u.do(ctx, http.MethodPut, fmt.Sprintf(apiUserGroupEndpoint, groupID), group, nil)
+}
+
+// ResetPassword sets the password and permissions of the user with the given ID.
+func (u *UserClient) ResetPassword(ctx context.Context, userID string, req ResetPasswordRequest) error {
+	return u.do(ctx, http.MethodPost, fmt.Sprintf(apiUsersActionsResetEndpoint, userID), req, nil)
+}
+
+// SetPortalCname sets the cname of the classic developer portal.
+func (u *UserClient) SetPortalCname(ctx context.Context, cname string) error {
+	return u.do(ctx, http.MethodPut, apiPortalCnameEndpoint, CnameRequest{Cname: cname}, nil)
+}
+
+// CreatePortalConfiguration creates the classic developer portal configuration with the Dashboard defaults.
+func (u *UserClient) CreatePortalConfiguration(ctx context.Context) error {
+	return u.do(ctx, http.MethodPost, apiPortalConfigurationEndpoint, nil, nil)
+}
+
+// GetPortalConfiguration returns the classic developer portal configuration, which has no "id" if it has
+// not been created yet.
+func (u *UserClient) GetPortalConfiguration(ctx context.Context) (RawObject, error) {
+	res := RawObject{}
+	err := u.do(ctx, http.MethodGet, apiPortalConfigurationEndpoint, nil, &res)
+	if StatusCode(err) == http.StatusNotFound {
+		return RawObject{}, nil
+	}
+
+	return res, err
+}
+
+// UpdatePortalConfiguration updates the classic developer portal configuration with the given ID.
+func (u *UserClient) UpdatePortalConfiguration(ctx context.Context, configID string, config RawObject) error {
+	return u.do(ctx, http.MethodPut, fmt.Sprintf(apiPortalConfigEndpoint, configID), config, nil)
+}
+
+// SetPortalCSS sets the custom stylesheet of the classic developer portal.
+func (u *UserClient) SetPortalCSS(ctx context.Context, css PortalCSS) error {
+	return u.do(ctx, http.MethodPut, apiPortalCSSEndpoint, css, nil)
+}
+
+// SetPortalJS sets the custom script of the classic developer portal.
+func (u *UserClient) SetPortalJS(ctx context.Context, js PortalJS) error {
+	return u.do(ctx, http.MethodPut, apiPortalJSEndpoint, js, nil)
+}
+
+// SetPortalMenus sets the menus of the classic developer portal.
+func (u *UserClient) SetPortalMenus(ctx context.Context, menus PortalMenus) error {
+	return u.do(ctx, http.MethodPut, apiPortalMenusEndpoint, menus, nil)
+}
+
+// CreateCatalogue creates the classic developer portal catalogue of the given organisation and returns
+// its ID.
+func (u *UserClient) CreateCatalogue(ctx context.Context, orgID string) (string, error) {
+	res := GeneralResponse{}
+	if err := u.do(ctx, http.MethodPost, apiPortalCatalogueEndpoint, InitCatalogueRequest{OrgID: orgID}, &res); err != nil {
+		return "", err
+	}
+
+	return res.Message, nil
+}
+
+// GetCatalogue returns the classic developer portal catalogue, with its APIs under "apis", or an empty
+// object if the organisation has no catalogue yet.
+func (u *UserClient) GetCatalogue(ctx context.Context) (RawObject, error) {
+	res := RawObject{}
+	err := u.do(ctx, http.MethodGet, apiPortalCatalogueEndpoint, nil, &res)
+	if StatusCode(err) == http.StatusNotFound {
+		return RawObject{}, nil
+	}
+
+	return res, err
+}
+
+// UpdateCatalogue replaces the classic developer portal catalogue.
+func (u *UserClient) UpdateCatalogue(ctx context.Context, catalogue RawObject) error {
+	return u.do(ctx, http.MethodPut, apiPortalCatalogueEndpoint, catalogue, nil)
+}
+
+// GetDocumentation returns the documentation of a catalogue API with the given ID.
+func (u *UserClient) GetDocumentation(ctx context.Context, docID string) (Documentation, error) {
+	res := Documentation{}
+	err := u.do(ctx, http.MethodGet, fmt.Sprintf(apiPortalDocumentEndpoint, docID), nil, &res)
+
+	return res, err
+}
+
+// DeleteDocumentation deletes the documentation of a catalogue API with the given ID.
+func (u *UserClient) DeleteDocumentation(ctx context.Context, docID string) error {
+	return u.do(ctx, http.MethodDelete, fmt.Sprintf(apiPortalDocumentEndpoint, docID), nil, nil)
+}
+
+// CreateDocumentation uploads the documentation of a catalogue API and returns its ID.
+func (u *UserClient) CreateDocumentation(ctx context.Context, req CreateDocumentationRequest) (string, error) {
+	res := GeneralResponse{}
+	if err := u.do(ctx, http.MethodPost, apiPortalDocumentationEndpoint, req, &res); err != nil {
+		return "", err
+	}
+
+	return res.Message, nil
+}
+
+// CreatePortalPage creates a classic developer portal page and returns its ID.
+func (u *UserClient) CreatePortalPage(ctx context.Context, page PortalPage) (string, error) {
+	res := GeneralResponse{}
+	if err := u.do(ctx, http.MethodPost, apiPortalPagesEndpoint, page, &res); err != nil {
+		return "", err
+	}
+
+	return res.Message, nil
+}
+
+// ListPortalPages returns the classic developer portal pages.
+func (u *UserClient) ListPortalPages(ctx context.Context) ([]PortalPage, error) {
+	res := PortalPagesResponse{}
+	if err := u.do(ctx, http.MethodGet, apiPortalPagesEndpoint, nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Pages, nil
+}
+
+// UpdatePortalPage updates the classic developer portal page with the given ID.
+func (u *UserClient) UpdatePortalPage(ctx context.Context, pageID string, page PortalPage) error {
+	return u.do(ctx, http.MethodPut, fmt.Sprintf(apiPortalPageEndpoint, pageID), page, nil)
+}
@@ -2,146 +2,584 @@ package data
 
 import (
 	"context"
+	"crypto/x509"
+	"flag"
 	"fmt"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/k8s"
+	"tyk/tyk/bootstrap/retry"
 )
 
 type AppArguments struct {
-	DashboardHost                string
-	DashboardPort                int32
-	DashBoardLicense             string
-	TykAdminSecret               string
-	CurrentOrgName               string
-	TykAdminPassword             string
-	Cname                        string
-	TykAdminFirstName            string
-	TykAdminLastName             string
-	TykAdminEmailAddress         string
-	UserAuth                     string
-	OrgId                        string
-	CatalogId                    string
-	DashboardUrl                 string
-	DashboardProto               string
-	TykPodNamespace              string
-	DashboardSvc                 string
-	DashboardInsecureSkipVerify  bool
-	IsDashboardEnabled           bool
-	OperatorSecretEnabled        bool
-	OperatorSecretName           string
-	DeveloperPortalSecretEnabled bool
-	DeveloperPortalSecretName    string
-	BootstrapPortal              bool
-	DashboardDeploymentName      string
+	DashboardHost               string
+	DashboardPort               int32
+	DashBoardLicense            string
+	TykAdminSecret              string
+	CurrentOrgName              string
+	TykAdminPassword            string
+	Cname                       string
+	TykAdminFirstName           string
+	TykAdminLastName            string
+	TykAdminEmailAddress        string
+	DashboardUrl                string
+	DashboardProto              string
+	TykPodNamespace             string
+	DashboardSvc                string
+	DashboardInsecureSkipVerify bool
+	// DashboardCACert is the PEM encoded CA bundle the Dashboard certificate is verified with, in addition to
+	// the system roots, and DashboardRootCAs holds its parsed certificates.
+	DashboardCACert                        string
+	DashboardRootCAs                       *x509.CertPool
+	IsDashboardEnabled                     bool
+	OperatorSecretEnabled                  bool
+	OperatorSecretName                     string
+	OperatorSecretNamespaces               []string
+	OperatorSecretNamespaceSelector        string
+	OperatorSecretExtraData                map[string]string
+	OperatorContextEnabled                 bool
+	OperatorContextName                    string
+	OperatorContextNamespace               string
+	DeveloperPortalSecretEnabled           bool
+	DeveloperPortalSecretName              string
+	DeveloperPortalSecretNamespaces        []string
+	DeveloperPortalSecretNamespaceSelector string
+	BootstrapPortal                        bool
+	// PortalMode is the portal bootstrapped for the organisations with BootstrapPortal set, one of
+	// constants.PortalModeClassic and constants.PortalModeEnterprise.
+	PortalMode                            string
+	EnterprisePortalUrl                   string
+	EnterprisePortalHealthTimeout         time.Duration
+	EnterprisePortalAdminFirstName        string
+	EnterprisePortalAdminLastName         string
+	EnterprisePortalAdminEmailAddress     string
+	EnterprisePortalAdminPassword         string
+	EnterprisePortalCredentialsSecretName string
+	EnterprisePortalCACert                string
+	EnterprisePortalRootCAs               *x509.CertPool
+	EnterprisePortalInsecureSkipVerify    bool
+	DashboardDeploymentName               string
+	AdoptExistingOrg                      bool
+	GatewayReplicas                       int
+	MdcbEnabled                           bool
+	LicenseEntitlementsStrict             bool
+	LicenseExpiryWarningThreshold         time.Duration
+	LicenseExpiryStrict                   bool
+	ReadinessPodSelectors                 []string
+	ReadinessOptionalPodSelectors         []string
+	ReadinessDeploymentSelectors          []string
+	ReadinessDeployments                  []string
+	ReadinessStatefulSets                 []string
+	ReadinessTimeout                      time.Duration
+	DashboardHealthTimeout                time.Duration
+	// DashboardRolloutTimeout is how long the rollout of the Dashboard is waited for, once restarted.
+	DashboardRolloutTimeout    time.Duration
+	RetryPolicy                retry.Policy
+	PortalHomepage             *PortalPage
+	PortalConfiguration        *PortalConfigurationSpec
+	PortalPagesDir             string
+	GenerateAdminPassword      bool
+	AdminCredentialsSecretName string
+	HelmReleaseName            string
+	HelmReleaseNamespace       string
+	SecretOwnerReferences      bool
+	Organisations              []*Organisation
+	// SecretTargets are the operator and portal secrets of all organisations, and OperatorContextNamespaces
+	// the namespaces of their enabled OperatorContexts, cleaned up by the pre delete hook.
+	SecretTargets             []SecretTarget
+	OperatorContextNamespaces []string
 }
 
 var AppConfig = AppArguments{
-	DashboardPort:        3000,
-	TykAdminSecret:       "12345",
-	CurrentOrgName:       "TYKTYK",
-	Cname:                "tykCName",
-	TykAdminPassword:     "123456",
-	TykAdminFirstName:    "firstName",
-	TykAdminEmailAddress: "tyk@tyk.io",
-	TykAdminLastName:     "lastName",
+	DashboardPort:                         3000,
+	LicenseExpiryWarningThreshold:         30 * 24 * time.Hour,
+	ReadinessTimeout:                      6 * time.Minute,
+	DashboardHealthTimeout:                5 * time.Minute,
+	DashboardRolloutTimeout:               5 * time.Minute,
+	RetryPolicy:                           retry.DefaultPolicy(),
+	AdminCredentialsSecretName:            "tyk-admin-credentials",
+	PortalMode:                            constants.PortalModeClassic,
+	EnterprisePortalHealthTimeout:         5 * time.Minute,
+	EnterprisePortalCredentialsSecretName: "tyk-enterprise-portal-credentials",
 }
 
-func InitAppDataPreDelete() error {
-	AppConfig.OperatorSecretName = os.Getenv(constants.OperatorSecretNameEnvVar)
-	AppConfig.DeveloperPortalSecretName = os.Getenv(constants.DeveloperPortalSecretNameEnvVar)
-	AppConfig.TykPodNamespace = os.Getenv(constants.TykPodNamespaceEnvVar)
-	return nil
+var (
+	// dashboardUrlFlag holds the value of the --dashboard-url flag.
+	dashboardUrlFlag string
+	// configFileFlag holds the value of the --config flag.
+	configFileFlag string
+)
+
+// BindFlags registers the flags overriding the application data on fs.
+func BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&dashboardUrlFlag, "dashboard-url", "",
+		fmt.Sprintf("URL of Tyk Dashboard, bypassing the discovery of its Service (overrides %v)",
+			constants.TykDashboardUrlEnvVar))
+	fs.StringVar(&configFileFlag, "config", "",
+		fmt.Sprintf("Path to a YAML or JSON bootstrap config file (overrides %v)",
+			constants.BootstrapConfigFileEnvVar))
 }
 
-func InitAppDataPostInstall() error {
-	AppConfig.TykAdminFirstName = os.Getenv(constants.TykAdminFirstNameEnvVar)
-	AppConfig.TykAdminLastName = os.Getenv(constants.TykAdminLastNameEnvVar)
-	AppConfig.TykAdminEmailAddress = os.Getenv(constants.TykAdminEmailEnvVar)
-	AppConfig.TykAdminPassword = os.Getenv(constants.TykAdminPasswordEnvVar)
-	AppConfig.TykPodNamespace = os.Getenv(constants.TykPodNamespaceEnvVar)
-	AppConfig.DashboardProto = os.Getenv(constants.TykDashboardProtoEnvVar)
+func InitAppDataPreInstall() error {
+	if err := initRetryPolicy(); err != nil {
+		return err
+	}
 
-	AppConfig.DashBoardLicense = os.Getenv(constants.TykDbLicensekeyEnvVar)
-	AppConfig.TykAdminSecret = os.Getenv(constants.TykAdminSecretEnvVar)
-	AppConfig.CurrentOrgName = os.Getenv(constants.TykOrgNameEnvVar)
-	AppConfig.Cname = os.Getenv(constants.TykOrgCnameEnvVar)
+	// The namespace is only needed to report warnings on the Job and to read the license from a Secret, so
+	// it is not looked up from kubeconfig.
+	AppConfig.TykPodNamespace = os.Getenv(constants.TykPodNamespaceEnvVar)
 
-	var err error
+	errs := &fieldErrors{}
+	resolveSensitiveValues(errs, []sensitiveValue{
+		{"dashboard.license", constants.TykDbLicensekeyEnvVar, &AppConfig.DashBoardLicense, nil},
+	})
+	if err := errs.err(); err != nil {
+		return err
+	}
 
-	dashEnabledRaw := os.Getenv(constants.DashboardEnabledEnvVar)
-	if dashEnabledRaw != "" {
-		AppConfig.IsDashboardEnabled, err = strconv.ParseBool(os.Getenv(constants.DashboardEnabledEnvVar))
+	gatewayReplicasRaw := os.Getenv(constants.TykGatewayReplicasEnvVar)
+	if gatewayReplicasRaw != "" {
+		gatewayReplicas, err := strconv.Atoi(gatewayReplicasRaw)
 		if err != nil {
-			return fmt.Errorf("failed to parse %v, err: %v", constants.DashboardEnabledEnvVar, err)
+			return fmt.Errorf("failed to parse %v, err: %v", constants.TykGatewayReplicasEnvVar, err)
 		}
+		AppConfig.GatewayReplicas = gatewayReplicas
 	}
 
-	if AppConfig.IsDashboardEnabled {
-		if err := discoverDashboardSvc(); err != nil {
+	boolEnvVars := []struct {
+		name  string
+		value *bool
+	}{
+		{constants.BootstrapPortalEnvVar, &AppConfig.BootstrapPortal},
+		{constants.OperatorSecretEnabledEnvVar, &AppConfig.OperatorSecretEnabled},
+		{constants.TykMdcbEnabledEnvVar, &AppConfig.MdcbEnabled},
+		{constants.LicenseEntitlementsStrictEnvVar, &AppConfig.LicenseEntitlementsStrict},
+	}
+	for _, envVar := range boolEnvVars {
+		if err := parseBoolEnvVar(envVar.name, envVar.value); err != nil {
 			return err
 		}
-		AppConfig.DashboardUrl = fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d",
-			AppConfig.DashboardProto,
-			AppConfig.DashboardSvc,
-			AppConfig.TykPodNamespace,
-			AppConfig.DashboardPort,
-		)
 	}
 
-	operatorSecretEnabledRaw := os.Getenv(constants.OperatorSecretEnabledEnvVar)
-	if operatorSecretEnabledRaw != "" {
-		AppConfig.OperatorSecretEnabled, err = strconv.ParseBool(operatorSecretEnabledRaw)
+	return initLicenseExpiry()
+}
+
+// initReadinessTargets reads the workloads that must be ready before bootstrapping, overriding the ones of
+// the config file. Label selectors are separated by semicolons, since they may contain commas, and names
+// are separated by commas. Unless configured otherwise, the Tyk Dashboard Deployment is selected by its
+// constants.TykBootstrapLabel label.
+func initReadinessTargets(errs *fieldErrors) {
+	listEnvVars := []struct {
+		name  string
+		sep   string
+		value *[]string
+	}{
+		{constants.ReadinessDeploymentsEnvVar, ",", &AppConfig.ReadinessDeployments},
+		{constants.ReadinessStatefulSetsEnvVar, ",", &AppConfig.ReadinessStatefulSets},
+	}
+	for _, envVar := range listEnvVars {
+		if raw := os.Getenv(envVar.name); raw != "" {
+			*envVar.value = splitList(raw, envVar.sep)
+		}
+	}
+
+	// The Redis Pods are waited for by default, as Redis is reached by the Dashboard. Redis may be deployed
+	// outside the namespace, so the default selector is optional.
+	podSelectorsRaw, ok := os.LookupEnv(constants.ReadinessPodSelectorsEnvVar)
+	if ok {
+		AppConfig.ReadinessPodSelectors = splitList(podSelectorsRaw, ";")
+	} else if AppConfig.ReadinessPodSelectors == nil {
+		AppConfig.ReadinessOptionalPodSelectors = []string{constants.RedisPodSelector}
+	}
+
+	deploymentSelectorsRaw, ok := os.LookupEnv(constants.ReadinessDeploymentSelectorsEnvVar)
+	if ok {
+		AppConfig.ReadinessDeploymentSelectors = splitList(deploymentSelectorsRaw, ";")
+	} else if AppConfig.ReadinessDeploymentSelectors == nil && AppConfig.IsDashboardEnabled {
+		AppConfig.ReadinessDeploymentSelectors = []string{
+			labels.Set{constants.TykBootstrapLabel: constants.TykBootstrapDashboardDeployLabel}.String(),
+		}
+	}
+
+	errs.addErr(parseDurationEnvVar(constants.ReadinessTimeoutEnvVar, &AppConfig.ReadinessTimeout))
+}
+
+// splitList splits raw by sep, dropping empty items.
+func splitList(raw, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// initLicenseExpiry reads the settings of the license expiry warning.
+func initLicenseExpiry() error {
+	thresholdRaw := os.Getenv(constants.LicenseExpiryWarningThresholdEnvVar)
+	if thresholdRaw != "" {
+		threshold, err := time.ParseDuration(thresholdRaw)
 		if err != nil {
-			return fmt.Errorf("failed to parse %v, err: %v", constants.OperatorSecretEnabledEnvVar, err)
+			return fmt.Errorf("failed to parse %v, err: %v", constants.LicenseExpiryWarningThresholdEnvVar, err)
 		}
+		AppConfig.LicenseExpiryWarningThreshold = threshold
+	}
+
+	return parseBoolEnvVar(constants.LicenseExpiryStrictEnvVar, &AppConfig.LicenseExpiryStrict)
+}
+
+// parseBoolEnvVar parses the env var with the given name into value, leaving value untouched if the env var
+// is not set.
+func parseBoolEnvVar(name string, value *bool) error {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return nil
+	}
+
+	parsed, err := strconv.ParseBool(raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse %v, err: %v", name, err)
+	}
+	*value = parsed
+
+	return nil
+}
+
+// parseDurationEnvVar parses the env var with the given name into value, leaving value untouched if the env
+// var is not set.
+func parseDurationEnvVar(name string, value *time.Duration) error {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse %v, err: %v", name, err)
+	}
+	*value = parsed
+
+	return nil
+}
+
+func InitAppDataPreDelete() error {
+	if err := initRetryPolicy(); err != nil {
+		return err
+	}
+
+	spec, err := loadConfigFile()
+	if err != nil {
+		return err
 	}
 
 	AppConfig.OperatorSecretName = os.Getenv(constants.OperatorSecretNameEnvVar)
+	AppConfig.DeveloperPortalSecretName = os.Getenv(constants.DeveloperPortalSecretNameEnvVar)
+	initSecretNamespaces()
+	if err := parseBoolEnvVar(constants.OperatorContextEnabledEnvVar, &AppConfig.OperatorContextEnabled); err != nil {
+		return err
+	}
+
+	if err := initPodNamespace(); err != nil {
+		return err
+	}
+	initHelmRelease()
+
+	AppConfig.SecretTargets = []SecretTarget{
+		{Name: AppConfig.OperatorSecretName, Namespaces: AppConfig.OperatorSecretNamespaces,
+			NamespaceSelector: AppConfig.OperatorSecretNamespaceSelector},
+		{Name: AppConfig.DeveloperPortalSecretName, Namespaces: AppConfig.DeveloperPortalSecretNamespaces,
+			NamespaceSelector: AppConfig.DeveloperPortalSecretNamespaceSelector},
+	}
+	contextNamespace := func(ns string) string {
+		if ns == "" {
+			return AppConfig.TykPodNamespace
+		}
+		return ns
+	}
+
+	AppConfig.OperatorContextNamespaces = nil
+	if AppConfig.OperatorContextEnabled {
+		AppConfig.OperatorContextNamespaces = append(AppConfig.OperatorContextNamespaces,
+			contextNamespace(AppConfig.OperatorContextNamespace))
+	}
+	if spec != nil {
+		for _, tenant := range spec.Organisations {
+			AppConfig.SecretTargets = append(AppConfig.SecretTargets,
+				tenant.OperatorSecret.target(), tenant.PortalSecret.target())
+			if c := tenant.OperatorSecret.Context; c != nil && c.Enabled != nil && *c.Enabled {
+				AppConfig.OperatorContextNamespaces = append(AppConfig.OperatorContextNamespaces,
+					contextNamespace(c.Namespace))
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadConfigFile loads the bootstrap config file given by --config or BOOTSTRAP_CONFIG_FILE, if any, and
+// applies it to AppConfig.
+func loadConfigFile() (*Spec, error) {
+	configFile := os.Getenv(constants.BootstrapConfigFileEnvVar)
+	if configFileFlag != "" {
+		configFile = configFileFlag
+	}
+	if configFile == "" {
+		return nil, nil
+	}
+
+	spec, err := LoadSpec(configFile)
+	if err != nil {
+		return nil, err
+	}
+	spec.apply(&AppConfig)
+
+	return spec, nil
+}
+
+// initSecretNamespaces reads the namespaces the operator and portal secrets are written to, given as comma
+// separated lists of namespaces and label selectors of namespaces, and the namespace of the OperatorContext.
+func initSecretNamespaces() {
+	listEnvVars := []struct {
+		name  string
+		value *[]string
+	}{
+		{constants.OperatorSecretNamespacesEnvVar, &AppConfig.OperatorSecretNamespaces},
+		{constants.DeveloperPortalSecretNamespacesEnvVar, &AppConfig.DeveloperPortalSecretNamespaces},
+	}
+	for _, envVar := range listEnvVars {
+		if raw := os.Getenv(envVar.name); raw != "" {
+			*envVar.value = splitList(raw, ",")
+		}
+	}
+
+	stringEnvVars := []struct {
+		name  string
+		value *string
+	}{
+		{constants.OperatorContextNamespaceEnvVar, &AppConfig.OperatorContextNamespace},
+		{constants.OperatorSecretNamespaceSelectorEnvVar, &AppConfig.OperatorSecretNamespaceSelector},
+		{constants.DeveloperPortalSecretNamespaceSelectorEnvVar, &AppConfig.DeveloperPortalSecretNamespaceSelector},
+	}
+	for _, envVar := range stringEnvVars {
+		if raw := os.Getenv(envVar.name); raw != "" {
+			*envVar.value = raw
+		}
+	}
+}
 
-	developerPortalSecretEnabledRaw := os.Getenv(constants.DeveloperPortalSecretEnabledEnvVar)
-	if developerPortalSecretEnabledRaw != "" {
-		AppConfig.DeveloperPortalSecretEnabled, err = strconv.ParseBool(developerPortalSecretEnabledRaw)
+// initHelmRelease reads the Helm release the generated Secrets belong to, if any. Its namespace defaults to
+// the Tyk namespace.
+func initHelmRelease() {
+	AppConfig.HelmReleaseName = os.Getenv(constants.HelmReleaseNameEnvVar)
+	AppConfig.HelmReleaseNamespace = os.Getenv(constants.HelmReleaseNamespaceEnvVar)
+	if AppConfig.HelmReleaseNamespace == "" {
+		AppConfig.HelmReleaseNamespace = AppConfig.TykPodNamespace
+	}
+}
+
+// initRetryPolicy reads the policy applied to Tyk Dashboard and Kubernetes API calls, and applies it to the
+// Kubernetes client. It must run before any Kubernetes client is created.
+func initRetryPolicy() error {
+	policy := &AppConfig.RetryPolicy
+
+	maxAttemptsRaw := os.Getenv(constants.RetryMaxAttemptsEnvVar)
+	if maxAttemptsRaw != "" {
+		maxAttempts, err := strconv.Atoi(maxAttemptsRaw)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to parse %v, err: %v", constants.RetryMaxAttemptsEnvVar, err)
 		}
+		policy.MaxAttempts = maxAttempts
 	}
-	AppConfig.DeveloperPortalSecretName = os.Getenv(constants.DeveloperPortalSecretNameEnvVar)
 
-	bootstrapPortalBoolRaw := os.Getenv(constants.BootstrapPortalEnvVar)
-	if bootstrapPortalBoolRaw != "" {
-		AppConfig.BootstrapPortal, err = strconv.ParseBool(bootstrapPortalBoolRaw)
+	durationEnvVars := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{constants.RetryInitialBackoffEnvVar, &policy.InitialBackoff},
+		{constants.RetryMaxBackoffEnvVar, &policy.MaxBackoff},
+		{constants.RequestTimeoutEnvVar, &policy.Timeout},
+	}
+	for _, envVar := range durationEnvVars {
+		raw := os.Getenv(envVar.name)
+		if raw == "" {
+			continue
+		}
+
+		d, err := time.ParseDuration(raw)
 		if err != nil {
-			return fmt.Errorf("failed to parse %v, err: %v", constants.BootstrapPortalEnvVar, err)
+			return fmt.Errorf("failed to parse %v, err: %v", envVar.name, err)
 		}
+		*envVar.value = d
 	}
-	AppConfig.DashboardDeploymentName = os.Getenv(constants.TykDashboardDeployEnvVar)
 
-	dashboardInsecureSkipVerifyRaw := os.Getenv(constants.TykDashboardInsecureSkipVerify)
-	if dashboardInsecureSkipVerifyRaw != "" {
-		AppConfig.DashboardInsecureSkipVerify, err = strconv.ParseBool(dashboardInsecureSkipVerifyRaw)
+	jitterRaw := os.Getenv(constants.RetryJitterEnvVar)
+	if jitterRaw != "" {
+		jitter, err := strconv.ParseFloat(jitterRaw, 64)
 		if err != nil {
-			return fmt.Errorf("failed to parse %v, err: %v", constants.TykDashboardInsecureSkipVerify, err)
+			return fmt.Errorf("failed to parse %v, err: %v", constants.RetryJitterEnvVar, err)
+		}
+		policy.Jitter = jitter
+	}
+
+	statusCodesRaw, ok := os.LookupEnv(constants.RetryStatusCodesEnvVar)
+	if ok {
+		policy.RetryableStatusCodes = nil
+		for _, codeRaw := range splitList(statusCodesRaw, ",") {
+			code, err := strconv.Atoi(codeRaw)
+			if err != nil {
+				return fmt.Errorf("failed to parse %v, err: %v", constants.RetryStatusCodesEnvVar, err)
+			}
+			policy.RetryableStatusCodes = append(policy.RetryableStatusCodes, code)
 		}
 	}
 
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("invalid retry policy, err: %v", err)
+	}
+
+	k8s.SetRetryPolicy(*policy)
+
 	return nil
 }
 
-// discoverDashboardSvc lists Service objects with constants.TykBootstrapReleaseLabel label that has
-// constants.TykBootstrapDashboardSvcLabel value and gets this Service's metadata name, and port and
-// updates DashboardSvc and DashboardPort fields.
-func discoverDashboardSvc() error {
-	config, err := rest.InClusterConfig()
+// initPodNamespace reads the namespace Tyk is deployed to. If it is not set, which is the case when running
+// outside of a cluster, the namespace of the kubeconfig context is used.
+func initPodNamespace() error {
+	AppConfig.TykPodNamespace = os.Getenv(constants.TykPodNamespaceEnvVar)
+	if AppConfig.TykPodNamespace != "" {
+		return nil
+	}
+
+	ns, err := k8s.Namespace()
 	if err != nil {
+		return fmt.Errorf("%v is not set and failed to read namespace from kubeconfig, err: %v",
+			constants.TykPodNamespaceEnvVar, err)
+	}
+	AppConfig.TykPodNamespace = ns
+
+	return nil
+}
+
+// InitAppDataPostInstall reads the configuration of the post install hook. Settings are read from the
+// bootstrap config file, if any, then overridden by the env vars that are set. All missing or malformed
+// settings are reported at once, before any API call is made.
+func InitAppDataPostInstall() error {
+	err := initRetryPolicy()
+	if err != nil {
+		return err
+	}
+
+	spec, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+
+	// The namespace is needed to read sensitive values from Secrets.
+	err = initPodNamespace()
+	if err != nil {
+		return err
+	}
+	initHelmRelease()
+
+	stringEnvVars := []struct {
+		name  string
+		value *string
+	}{
+		{constants.TykAdminFirstNameEnvVar, &AppConfig.TykAdminFirstName},
+		{constants.TykAdminLastNameEnvVar, &AppConfig.TykAdminLastName},
+		{constants.TykAdminEmailEnvVar, &AppConfig.TykAdminEmailAddress},
+		{constants.TykDashboardProtoEnvVar, &AppConfig.DashboardProto},
+		{constants.TykOrgNameEnvVar, &AppConfig.CurrentOrgName},
+		{constants.TykOrgCnameEnvVar, &AppConfig.Cname},
+		{constants.TykDashboardUrlEnvVar, &AppConfig.DashboardUrl},
+		{constants.OperatorSecretNameEnvVar, &AppConfig.OperatorSecretName},
+		{constants.DeveloperPortalSecretNameEnvVar, &AppConfig.DeveloperPortalSecretName},
+		{constants.TykDashboardDeployEnvVar, &AppConfig.DashboardDeploymentName},
+		{constants.AdminCredentialsSecretNameEnvVar, &AppConfig.AdminCredentialsSecretName},
+		{constants.OperatorContextNameEnvVar, &AppConfig.OperatorContextName},
+		{constants.PortalPagesDirEnvVar, &AppConfig.PortalPagesDir},
+		{constants.PortalModeEnvVar, &AppConfig.PortalMode},
+		{constants.EnterprisePortalUrlEnvVar, &AppConfig.EnterprisePortalUrl},
+		{constants.EnterprisePortalAdminFirstNameEnvVar, &AppConfig.EnterprisePortalAdminFirstName},
+		{constants.EnterprisePortalAdminLastNameEnvVar, &AppConfig.EnterprisePortalAdminLastName},
+		{constants.EnterprisePortalAdminEmailEnvVar, &AppConfig.EnterprisePortalAdminEmailAddress},
+		{constants.EnterprisePortalCredentialsSecretNameEnvVar, &AppConfig.EnterprisePortalCredentialsSecretName},
+	}
+	for _, envVar := range stringEnvVars {
+		if raw := os.Getenv(envVar.name); raw != "" {
+			*envVar.value = raw
+		}
+	}
+
+	initSecretNamespaces()
+
+	if dashboardUrlFlag != "" {
+		AppConfig.DashboardUrl = dashboardUrlFlag
+	}
+
+	errs := &fieldErrors{}
+	resolveSensitiveValues(errs, spec.sensitiveValues(&AppConfig))
+
+	boolEnvVars := []struct {
+		name  string
+		value *bool
+	}{
+		{constants.DashboardEnabledEnvVar, &AppConfig.IsDashboardEnabled},
+		{constants.OperatorSecretEnabledEnvVar, &AppConfig.OperatorSecretEnabled},
+		{constants.DeveloperPortalSecretEnabledEnvVar, &AppConfig.DeveloperPortalSecretEnabled},
+		{constants.BootstrapPortalEnvVar, &AppConfig.BootstrapPortal},
+		{constants.AdoptExistingOrgEnvVar, &AppConfig.AdoptExistingOrg},
+		{constants.TykDashboardInsecureSkipVerify, &AppConfig.DashboardInsecureSkipVerify},
+		{constants.EnterprisePortalInsecureSkipVerifyEnvVar, &AppConfig.EnterprisePortalInsecureSkipVerify},
+		{constants.TykAdminPasswordGenerateEnvVar, &AppConfig.GenerateAdminPassword},
+		{constants.SecretOwnerReferencesEnvVar, &AppConfig.SecretOwnerReferences},
+		{constants.OperatorContextEnabledEnvVar, &AppConfig.OperatorContextEnabled},
+	}
+	for _, envVar := range boolEnvVars {
+		errs.addErr(parseBoolEnvVar(envVar.name, envVar.value))
+	}
+
+	initReadinessTargets(errs)
+	errs.addErr(parseDurationEnvVar(constants.DashboardHealthTimeoutEnvVar, &AppConfig.DashboardHealthTimeout))
+	errs.addErr(parseDurationEnvVar(constants.DashboardRolloutTimeoutEnvVar, &AppConfig.DashboardRolloutTimeout))
+	errs.addErr(parseDurationEnvVar(constants.EnterprisePortalHealthTimeoutEnvVar,
+		&AppConfig.EnterprisePortalHealthTimeout))
+	errs.addErr(initLicenseExpiry())
+
+	validatePostInstall(errs)
+	initOrganisations(errs, spec)
+	validateEnterprisePortal(errs)
+	if err = errs.err(); err != nil {
 		return err
 	}
 
-	c, err := kubernetes.NewForConfig(config)
+	if AppConfig.DashboardUrl == "" {
+		if err := discoverDashboardSvc(); err != nil {
+			return err
+		}
+		AppConfig.DashboardUrl = fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d",
+			AppConfig.DashboardProto,
+			AppConfig.DashboardSvc,
+			AppConfig.TykPodNamespace,
+			AppConfig.DashboardPort,
+		)
+	}
+
+	return nil
+}
+
+// discoverDashboardSvc lists Service objects with constants.TykBootstrapReleaseLabel label that has
+// constants.TykBootstrapDashboardSvcLabel value and gets this Service's metadata name, and port and
+// updates DashboardSvc and DashboardPort fields.
+func discoverDashboardSvc() error {
+	c, err := k8s.NewClientset()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,146 @@
+package data
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MinCredentialLength is the minimum length of the admin secret and of the admin password.
+	MinCredentialLength = 12
+
+	generatedPasswordLength   = 24
+	generatedPasswordAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789-_.!@#%^*+="
+)
+
+// weakCredentials are sample and commonly used values that are refused as admin secret or admin password,
+// regardless of their length. They are compared case-insensitively.
+var weakCredentials = []string{
+	"12345",
+	"123456",
+	"12345678",
+	"123456789",
+	"1234567890",
+	"123456789012",
+	"admin",
+	"administrator",
+	"changeme",
+	"default",
+	"letmein",
+	"password",
+	"password123",
+	"qwerty",
+	"qwertyuiop",
+	"secret",
+	"test",
+	"tyk",
+	"tyktyk",
+	"tykadmin",
+	"tyk-admin",
+}
+
+func isWeakCredential(value string) bool {
+	for _, weak := range weakCredentials {
+		if strings.EqualFold(value, weak) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckAdminSecret reports why secret is not acceptable as the Tyk Dashboard admin secret, if so.
+func CheckAdminSecret(secret string) error {
+	switch {
+	case isWeakCredential(secret):
+		return errors.New("must not be a well-known sample or weak value")
+	case len(secret) < MinCredentialLength:
+		return fmt.Errorf("must be at least %d characters long", MinCredentialLength)
+	}
+
+	return nil
+}
+
+// CheckPassword reports why password is not strong enough for the Dashboard user with the given email, if
+// so. A password must be long enough, use at least three of lower case letters, upper case letters, digits
+// and symbols, and must not contain the user's name.
+func CheckPassword(password, email string) error {
+	if isWeakCredential(password) {
+		return errors.New("must not be a well-known sample or weak value")
+	}
+
+	if len(password) < MinCredentialLength {
+		return fmt.Errorf("must be at least %d characters long", MinCredentialLength)
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	classes := 0
+	for _, used := range []bool{lower, upper, digit, symbol} {
+		if used {
+			classes++
+		}
+	}
+	if classes < 3 {
+		return errors.New("must use at least three of lower case letters, upper case letters, digits and symbols")
+	}
+
+	if name := strings.SplitN(email, "@", 2)[0]; len(name) >= 3 &&
+		strings.Contains(strings.ToLower(password), strings.ToLower(name)) {
+		return errors.New("must not contain the user's email name")
+	}
+
+	return nil
+}
+
+// CheckEmail reports why email is not a valid email address, if so.
+func CheckEmail(email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return fmt.Errorf("must be a plain email address such as admin@example.com, got %q", email)
+	}
+
+	if domain := email[strings.LastIndex(email, "@")+1:]; strings.HasPrefix(domain, ".") ||
+		strings.HasSuffix(domain, ".") {
+		return fmt.Errorf("invalid email domain %q", domain)
+	}
+
+	return nil
+}
+
+// GeneratePassword returns a random password satisfying CheckPassword for the given email.
+func GeneratePassword(email string) (string, error) {
+	max := big.NewInt(int64(len(generatedPasswordAlphabet)))
+
+	for {
+		password := make([]byte, generatedPasswordLength)
+		for i := range password {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", fmt.Errorf("failed to generate password, err: %v", err)
+			}
+			password[i] = generatedPasswordAlphabet[n.Int64()]
+		}
+
+		if CheckPassword(string(password), email) == nil {
+			return string(password), nil
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package data
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckAdminSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr string
+	}{
+		{name: "strong", secret: "c0rrect-horse-battery"},
+		{name: "exactly minimum length", secret: "abcdefghijkl"},
+		{name: "too short", secret: "abcdefghijk", wantErr: "at least 12 characters"},
+		{name: "empty", secret: "", wantErr: "at least 12 characters"},
+		{name: "weak", secret: "tykadmin", wantErr: "well-known sample or weak value"},
+		{name: "weak despite length", secret: "123456789012", wantErr: "well-known sample or weak value"},
+		{name: "weak in other case", secret: "PASSWORD123", wantErr: "well-known sample or weak value"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			checkErr(t, CheckAdminSecret(tc.secret), tc.wantErr)
+		})
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		email    string
+		wantErr  string
+	}{
+		{name: "lower, upper and digits", password: "Abcdefgh1234", email: "admin@example.com"},
+		{name: "lower, digits and symbols", password: "abcdefgh12-!", email: "admin@example.com"},
+		{name: "all classes", password: "Abc-def-1234!", email: "admin@example.com"},
+		{name: "too short", password: "Abc-12", email: "admin@example.com", wantErr: "at least 12 characters"},
+		{name: "weak", password: "changeme", email: "admin@example.com", wantErr: "weak value"},
+		{name: "weak despite length", password: "Password123", email: "admin@example.com", wantErr: "weak value"},
+		{
+			name:     "two classes",
+			password: "abcdefgh1234",
+			email:    "admin@example.com",
+			wantErr:  "at least three of",
+		},
+		{
+			name:     "single class",
+			password: "abcdefghijklmnop",
+			email:    "admin@example.com",
+			wantErr:  "at least three of",
+		},
+		{
+			name:     "contains email name",
+			password: "Xx-JaneDoe-1234",
+			email:    "janedoe@example.com",
+			wantErr:  "must not contain the user's email name",
+		},
+		{name: "short email name is ignored", password: "Abcdefgh1234", email: "ab@example.com"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			checkErr(t, CheckPassword(tc.password, tc.email), tc.wantErr)
+		})
+	}
+}
+
+func TestCheckEmail(t *testing.T) {
+	tests := []struct {
+		email   string
+		wantErr string
+	}{
+		{email: "admin@example.com"},
+		{email: "first.last+tyk@sub.example.com"},
+		{email: "admin", wantErr: "plain email address"},
+		{email: "Admin <admin@example.com>", wantErr: "plain email address"},
+		{email: "admin@example.com.", wantErr: "example.com."},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.email, func(t *testing.T) {
+			checkErr(t, CheckEmail(tc.email), tc.wantErr)
+		})
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		password, err := GeneratePassword("admin@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := CheckPassword(password, "admin@example.com"); err != nil {
+			t.Fatalf("generated password %q is rejected, err: %v", password, err)
+		}
+	}
+}
+
+// checkErr fails t unless err contains wantErr, or is nil if wantErr is empty.
+func checkErr(t *testing.T, err error, wantErr string) {
+	t.Helper()
+
+	switch {
+	case wantErr == "" && err != nil:
+		t.Errorf("unexpected error %v", err)
+	case wantErr != "" && err == nil:
+		t.Errorf("expected error containing %q", wantErr)
+	case wantErr != "" && !strings.Contains(err.Error(), wantErr):
+		t.Errorf("expected error containing %q, got %v", wantErr, err)
+	}
+}
@@ -0,0 +1,672 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/k8s"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Organisation is an organisation bootstrapped by the post install hook, along with the state collected
+// while bootstrapping it.
+type Organisation struct {
+	Name          string
+	Cname         string
+	AdoptExisting bool
+	// AdminUsers are the admin users of the organisation. The first one configures the organisation, and
+	// its access key is stored in the portal secret and in its PasswordSecretName credentials secret, where
+	// it is looked up when the organisation is adopted.
+	AdminUsers []*User
+	// Users are the other users of the organisation, which may belong to one of its UserGroups.
+	Users          []*User
+	UserGroups     []*UserGroup
+	OperatorSecret SecretTarget
+	// OperatorSecretExtraData holds additional keys of the operator secret.
+	OperatorSecretExtraData map[string]string
+	// OperatorContext references the operator secret, if enabled.
+	OperatorContext OperatorContextTarget
+	// OperatorUser is the service user whose access key is stored in the operator secret, if it is enabled.
+	OperatorUser    *User
+	PortalSecret    SecretTarget
+	BootstrapPortal bool
+	PortalHomepage  *PortalPage
+	// PortalPages are the other pages of the portal, one of which may be its homepage.
+	PortalPages []*PortalPage
+	// PortalCatalogue lists the APIs published in the portal catalogue once it is created.
+	PortalCatalogue []*CatalogueEntry
+	// PortalConfiguration holds the settings of the portal, if any are configured.
+	PortalConfiguration *PortalConfigurationSpec
+
+	// ID is the ID of the organisation in Tyk Dashboard.
+	ID string
+	// UserAuth is the access key of the first admin user.
+	UserAuth string
+	// OperatorAuth is the access key of the operator service user.
+	OperatorAuth string
+	CatalogId    string
+}
+
+func (o *Organisation) String() string {
+	return fmt.Sprintf("%q", o.Name)
+}
+
+// User is a Tyk Dashboard user.
+type User struct {
+	FirstName    string
+	LastName     string
+	EmailAddress string
+	Password     string
+	// GeneratePassword enables the generation of Password, if it is not set, which is then stored in the
+	// PasswordSecretName Secret.
+	GeneratePassword   bool
+	PasswordSecretName string
+	// Permissions are the user_permissions of the user. Users of a group without explicit permissions
+	// have the permissions of the group.
+	Permissions map[string]string
+	// Group is the name of the user group of the user, if any.
+	Group string
+
+	// GroupID is the ID of the user group in Tyk Dashboard, set while bootstrapping.
+	GroupID string
+}
+
+// UserGroup is a Tyk Dashboard user group.
+type UserGroup struct {
+	Name        string
+	Description string
+	Permissions map[string]string
+
+	// ID is the ID of the user group in Tyk Dashboard, set while bootstrapping.
+	ID string
+}
+
+const (
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+	PermissionDeny  = "deny"
+
+	// PermissionIsAdmin is the permission making a user an admin of its organisation, with the value
+	// "admin".
+	PermissionIsAdmin = "IsAdmin"
+)
+
+// permissionObjects are the objects of Tyk Dashboard user permissions.
+var permissionObjects = []string{
+	"analytics", "api_assets", "apis", "audit_logs", "certs", "hooks", "idm", "keys", "log", "oauth",
+	"policies", "portal", "reports", "system", "user_groups", "users", "websockets",
+}
+
+// OperatorPermissions returns the default permissions of the operator service user, which manages APIs,
+// policies, portal catalogues and certificates.
+func OperatorPermissions() map[string]string {
+	return map[string]string{
+		"apis":     PermissionWrite,
+		"certs":    PermissionWrite,
+		"policies": PermissionWrite,
+		"portal":   PermissionWrite,
+	}
+}
+
+// operatorUser returns the operator service user of the organisation with the given name, as described by
+// spec, which may be nil.
+func operatorUser(orgName string, spec *OperatorUserSpec) *User {
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(orgName))
+
+	user := &User{
+		FirstName:    "Tyk",
+		LastName:     "Operator",
+		EmailAddress: fmt.Sprintf("tyk-operator-%v@bootstrap.tyk.invalid", strings.Trim(slug, "-")),
+		Permissions:  OperatorPermissions(),
+	}
+
+	if spec != nil {
+		stringFields := []struct {
+			value string
+			field *string
+		}{
+			{spec.FirstName, &user.FirstName},
+			{spec.LastName, &user.LastName},
+			{spec.EmailAddress, &user.EmailAddress},
+		}
+		for _, f := range stringFields {
+			if f.value != "" {
+				*f.field = f.value
+			}
+		}
+
+		if len(spec.Permissions) > 0 {
+			user.Permissions = spec.Permissions
+		}
+	}
+
+	return user
+}
+
+// AdminPermissions returns the permissions of admin users.
+func AdminPermissions() map[string]string {
+	return map[string]string{PermissionIsAdmin: "admin"}
+}
+
+// operatorSecretKeys are the keys of the operator secret written by helpers.BootstrapTykOperatorSecret,
+// which cannot be overridden.
+var operatorSecretKeys = []string{
+	"TYK_AUTH", "TYK_ORG", "TYK_MODE", "TYK_URL", "TYK_TLS_INSECURE_SKIP_VERIFY", "ca.crt",
+}
+
+// SecretTarget is a Secret written by the post install hook to several namespaces.
+type SecretTarget struct {
+	Enabled bool
+	Name    string
+	// Namespaces and the namespaces matching NamespaceSelector are the namespaces the Secret is written to.
+	Namespaces        []string
+	NamespaceSelector string
+}
+
+// ResolveNamespaces returns the namespaces the Secret is written to, in order and without duplicates,
+// listing the namespaces matching its selector.
+func (t SecretTarget) ResolveNamespaces(ctx context.Context) ([]string, error) {
+	namespaces := t.Namespaces
+	if t.NamespaceSelector != "" {
+		matching, err := k8s.NamespacesMatching(ctx, t.NamespaceSelector)
+		if err != nil {
+			return nil, err
+		}
+		if len(matching) == 0 {
+			fmt.Printf("No namespaces match the selector %q of secret %v\n", t.NamespaceSelector, t.Name)
+		}
+		namespaces = append(append([]string{}, namespaces...), matching...)
+	}
+
+	seen := map[string]bool{}
+	var unique []string
+	for _, ns := range namespaces {
+		if !seen[ns] {
+			seen[ns] = true
+			unique = append(unique, ns)
+		}
+	}
+
+	return unique, nil
+}
+
+// OperatorContextTarget is an OperatorContext custom resource written by the post install hook.
+type OperatorContextTarget struct {
+	Enabled   bool
+	Name      string
+	Namespace string
+}
+
+// fieldNamer returns the path of the setting with the given name in the bootstrap config file, along with
+// the env var overriding it, if any.
+type fieldNamer func(name string) (field, envVar string)
+
+func prefixNamer(prefix string) fieldNamer {
+	return func(name string) (string, string) {
+		return prefix + "." + name, ""
+	}
+}
+
+// primaryOrgFields name the settings of the organisation configured by the organisation, adminUser,
+// secrets and portal sections of the bootstrap config file, which env vars may override.
+var primaryOrgFields = map[string][2]string{
+	"name":                {"organisation.name", constants.TykOrgNameEnvVar},
+	"cname":               {"organisation.cname", constants.TykOrgCnameEnvVar},
+	"operatorSecret.name": {"secrets.operator.name", constants.OperatorSecretNameEnvVar},
+	"portalSecret.name":   {"secrets.portal.name", constants.DeveloperPortalSecretNameEnvVar},
+	"operatorSecret.namespaces": {"secrets.operator.namespaces",
+		constants.OperatorSecretNamespacesEnvVar},
+	"operatorSecret.namespaceSelector": {"secrets.operator.namespaceSelector",
+		constants.OperatorSecretNamespaceSelectorEnvVar},
+	"portalSecret.namespaces": {"secrets.portal.namespaces",
+		constants.DeveloperPortalSecretNamespacesEnvVar},
+	"portalSecret.namespaceSelector": {"secrets.portal.namespaceSelector",
+		constants.DeveloperPortalSecretNamespaceSelectorEnvVar},
+	"operatorSecret.extraData": {"secrets.operator.extraData", ""},
+	"operatorContext.enabled": {"secrets.operator.context.enabled",
+		constants.OperatorContextEnabledEnvVar},
+	"operatorContext.name": {"secrets.operator.context.name",
+		constants.OperatorContextNameEnvVar},
+	"operatorContext.namespace": {"secrets.operator.context.namespace",
+		constants.OperatorContextNamespaceEnvVar},
+	"portal.pagesDir":       {"portal.pagesDir", constants.PortalPagesDirEnvVar},
+	"portal.homepage.title": {"portal.homepage.title", ""},
+	"portal.homepage.slug":  {"portal.homepage.slug", ""},
+	"firstName":             {"adminUser.firstName", constants.TykAdminFirstNameEnvVar},
+	"lastName":              {"adminUser.lastName", constants.TykAdminLastNameEnvVar},
+	"email":                 {"adminUser.email", constants.TykAdminEmailEnvVar},
+	"password":              {"adminUser.password", constants.TykAdminPasswordEnvVar},
+	"generatePassword":      {"adminUser.generatePassword", constants.TykAdminPasswordGenerateEnvVar},
+	"passwordSecretName":    {"adminUser.passwordSecretName", constants.AdminCredentialsSecretNameEnvVar},
+}
+
+func primaryOrgNamer(name string) (string, string) {
+	f := primaryOrgFields[name]
+	return f[0], f[1]
+}
+
+// initOrganisations builds the organisations to bootstrap, in order. The organisation configured by the
+// flat settings of AppConfig comes first, unless the organisations section of the config file is used
+// and no organisation name is set.
+func initOrganisations(errs *fieldErrors, spec *Spec) {
+	AppConfig.Organisations = nil
+
+	var tenants []TenantSpec
+	if spec != nil {
+		tenants = spec.Organisations
+	}
+
+	if AppConfig.CurrentOrgName != "" || len(tenants) == 0 {
+		org := &Organisation{
+			Name:          AppConfig.CurrentOrgName,
+			Cname:         AppConfig.Cname,
+			AdoptExisting: AppConfig.AdoptExistingOrg,
+			AdminUsers: []*User{{
+				FirstName:          AppConfig.TykAdminFirstName,
+				LastName:           AppConfig.TykAdminLastName,
+				EmailAddress:       AppConfig.TykAdminEmailAddress,
+				Password:           AppConfig.TykAdminPassword,
+				GeneratePassword:   AppConfig.GenerateAdminPassword,
+				PasswordSecretName: AppConfig.AdminCredentialsSecretName,
+			}},
+			OperatorSecret: SecretTarget{
+				Enabled:           AppConfig.OperatorSecretEnabled,
+				Name:              AppConfig.OperatorSecretName,
+				Namespaces:        AppConfig.OperatorSecretNamespaces,
+				NamespaceSelector: AppConfig.OperatorSecretNamespaceSelector,
+			},
+			OperatorSecretExtraData: AppConfig.OperatorSecretExtraData,
+			OperatorContext: OperatorContextTarget{
+				Enabled:   AppConfig.OperatorContextEnabled,
+				Name:      AppConfig.OperatorContextName,
+				Namespace: AppConfig.OperatorContextNamespace,
+			},
+			PortalSecret: SecretTarget{
+				Enabled:           AppConfig.DeveloperPortalSecretEnabled,
+				Name:              AppConfig.DeveloperPortalSecretName,
+				Namespaces:        AppConfig.DeveloperPortalSecretNamespaces,
+				NamespaceSelector: AppConfig.DeveloperPortalSecretNamespaceSelector,
+			},
+			BootstrapPortal:     AppConfig.BootstrapPortal,
+			PortalHomepage:      AppConfig.PortalHomepage,
+			PortalConfiguration: AppConfig.PortalConfiguration,
+		}
+
+		org.AdminUsers[0].Permissions = AdminPermissions()
+
+		var operatorUserSpec *OperatorUserSpec
+		if spec != nil {
+			org.Users, org.UserGroups = usersAndGroups(errs, "", spec.Users, spec.UserGroups)
+			org.PortalCatalogue = catalogueEntries(errs, "portal.catalogue", spec.Portal.Catalogue)
+			operatorUserSpec = spec.Secrets.Operator.User
+		}
+		if org.OperatorSecret.Enabled {
+			org.OperatorUser = operatorUser(org.Name, operatorUserSpec)
+		}
+
+		userNamers := []fieldNamer{primaryOrgNamer}
+		for i := range org.Users {
+			userNamers = append(userNamers, prefixNamer(fmt.Sprintf("users[%d]", i)))
+		}
+
+		org.PortalPages = loadPortalPages(errs, primaryOrgNamer, AppConfig.PortalPagesDir)
+		validateOrganisation(errs, org, primaryOrgNamer, "", "secrets.operator.user", userNamers...)
+		AppConfig.Organisations = append(AppConfig.Organisations, org)
+	}
+
+	for i, tenant := range tenants {
+		prefix := fmt.Sprintf("organisations[%d]", i)
+		org := tenant.organisation(errs, prefix)
+		if len(org.AdminUsers) > 0 && org.AdminUsers[0].PasswordSecretName == "" {
+			org.AdminUsers[0].PasswordSecretName = tenantCredentialsSecretName(org.Name)
+		}
+
+		var userNamers []fieldNamer
+		for j := range org.AdminUsers {
+			userNamers = append(userNamers, prefixNamer(fmt.Sprintf("%v.adminUsers[%d]", prefix, j)))
+		}
+		for j := range org.Users {
+			userNamers = append(userNamers, prefixNamer(fmt.Sprintf("%v.users[%d]", prefix, j)))
+		}
+
+		org.PortalPages = loadPortalPages(errs, prefixNamer(prefix), tenant.Portal.PagesDir)
+		validateOrganisation(errs, org, prefixNamer(prefix), prefix+".", prefix+".operatorSecret.user",
+			userNamers...)
+		if len(org.AdminUsers) == 0 {
+			errs.add(prefix+".adminUsers", "", "at least one admin user is required")
+		}
+
+		AppConfig.Organisations = append(AppConfig.Organisations, org)
+	}
+
+	for _, org := range AppConfig.Organisations {
+		for _, secret := range []*SecretTarget{&org.OperatorSecret, &org.PortalSecret} {
+			if len(secret.Namespaces) == 0 && secret.NamespaceSelector == "" {
+				secret.Namespaces = []string{AppConfig.TykPodNamespace}
+			}
+		}
+
+		if org.OperatorContext.Name == "" {
+			org.OperatorContext.Name = org.OperatorSecret.Name
+		}
+		if org.OperatorContext.Namespace == "" {
+			org.OperatorContext.Namespace = AppConfig.TykPodNamespace
+		}
+	}
+
+	validateUniqueOrganisations(errs)
+}
+
+// validateOrganisation checks the settings of org. userNamers name the settings of the admin users and of
+// the other users, in order. The user groups are found after prefix in the config file, and the operator
+// service user at operatorUserField.
+func validateOrganisation(errs *fieldErrors, org *Organisation, namer fieldNamer, prefix,
+	operatorUserField string, userNamers ...fieldNamer) {
+	add := func(name, format string, args ...interface{}) {
+		field, envVar := namer(name)
+		errs.add(field, envVar, format, args...)
+	}
+
+	if org.Name == "" {
+		add("name", "required")
+	}
+	if org.BootstrapPortal && AppConfig.PortalMode == constants.PortalModeClassic && org.Cname == "" {
+		add("cname", "required when the classic portal is bootstrapped")
+	}
+
+	if org.OperatorSecret.Enabled && org.OperatorSecret.Name == "" {
+		add("operatorSecret.name", "required when the operator secret is enabled")
+	}
+	if org.PortalSecret.Enabled && org.PortalSecret.Name == "" {
+		add("portalSecret.name", "required when the portal secret is enabled")
+	}
+
+	secrets := []struct {
+		name   string
+		target SecretTarget
+	}{
+		{"operatorSecret", org.OperatorSecret},
+		{"portalSecret", org.PortalSecret},
+	}
+	for _, secret := range secrets {
+		if !secret.target.Enabled {
+			continue
+		}
+
+		for _, ns := range secret.target.Namespaces {
+			if problems := validation.IsDNS1123Label(ns); len(problems) > 0 {
+				add(secret.name+".namespaces", "invalid namespace %q: %v", ns, strings.Join(problems, ", "))
+			}
+		}
+
+		if secret.target.NamespaceSelector != "" {
+			if _, err := labels.Parse(secret.target.NamespaceSelector); err != nil {
+				add(secret.name+".namespaceSelector", "%v", err)
+			}
+		}
+	}
+
+	if c := org.OperatorContext; c.Enabled {
+		if !org.OperatorSecret.Enabled {
+			add("operatorContext.enabled", "requires the operator secret to be enabled")
+		}
+		if c.Name != "" {
+			if problems := validation.IsDNS1123Subdomain(c.Name); len(problems) > 0 {
+				add("operatorContext.name", "invalid name %q: %v", c.Name, strings.Join(problems, ", "))
+			}
+		}
+		if c.Namespace != "" {
+			if problems := validation.IsDNS1123Label(c.Namespace); len(problems) > 0 {
+				add("operatorContext.namespace", "invalid namespace %q: %v", c.Namespace,
+					strings.Join(problems, ", "))
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(org.OperatorSecretExtraData))
+	for key := range org.OperatorSecretExtraData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if problems := validation.IsConfigMapKey(key); len(problems) > 0 {
+			add("operatorSecret.extraData", "invalid key %q: %v", key, strings.Join(problems, ", "))
+		}
+		for _, reserved := range operatorSecretKeys {
+			if key == reserved {
+				add("operatorSecret.extraData", "key %v is set by the bootstrapping", key)
+			}
+		}
+	}
+
+	if page := org.PortalHomepage; page != nil {
+		if page.Title == "" {
+			add("portal.homepage.title", "required")
+		}
+		if !strings.HasPrefix(page.Slug, "/") {
+			add("portal.homepage.slug", "must start with /, got %q", page.Slug)
+		}
+	}
+	validatePortalPages(org, add)
+	validatePortalConfiguration(org.PortalConfiguration, add)
+
+	for i, user := range org.AdminUsers {
+		validateUser(errs, user, userNamers[i])
+	}
+
+	groups := map[string]*UserGroup{}
+	for i, group := range org.UserGroups {
+		field := fmt.Sprintf("%vuserGroups[%d]", prefix, i)
+		switch {
+		case group.Name == "":
+			errs.add(field+".name", "", "required")
+		case groups[group.Name] != nil:
+			errs.add(field+".name", "", "user group %q is declared more than once", group.Name)
+		}
+		groups[group.Name] = group
+
+		validatePermissions(errs, field+".permissions", group.Permissions)
+	}
+
+	for i, user := range org.Users {
+		userNamer := userNamers[len(org.AdminUsers)+i]
+		validateUser(errs, user, userNamer)
+
+		groupField, _ := userNamer("group")
+		permissionsField, _ := userNamer("permissions")
+		switch {
+		case user.Group != "" && groups[user.Group] == nil:
+			errs.add(groupField, "", "user group %q is not declared", user.Group)
+		case user.Group != "" && len(user.Permissions) == 0:
+			user.Permissions = groups[user.Group].Permissions
+		case user.Group == "" && len(user.Permissions) == 0:
+			errs.add(permissionsField, "", "required unless the user belongs to a user group")
+		}
+
+		validatePermissions(errs, permissionsField, user.Permissions)
+	}
+
+	if user := org.OperatorUser; user != nil {
+		if err := CheckEmail(user.EmailAddress); err != nil {
+			errs.add(operatorUserField+".email", "", "%v", err)
+		}
+		validatePermissions(errs, operatorUserField+".permissions", user.Permissions)
+	}
+}
+
+// validatePermissions checks that permissions grant read, write or deny access to known objects. Admin
+// permissions are reserved to admin users.
+func validatePermissions(errs *fieldErrors, field string, permissions map[string]string) {
+	objects := make([]string, 0, len(permissions))
+	for object := range permissions {
+		objects = append(objects, object)
+	}
+	sort.Strings(objects)
+
+	for _, object := range objects {
+		access := permissions[object]
+		if object == PermissionIsAdmin {
+			errs.add(field, "", "%v is reserved to admin users", PermissionIsAdmin)
+			continue
+		}
+
+		known := false
+		for _, o := range permissionObjects {
+			known = known || o == object
+		}
+		if !known {
+			errs.add(field, "", "unknown object %q, expected one of %v", object,
+				strings.Join(permissionObjects, ", "))
+		}
+
+		if access != PermissionRead && access != PermissionWrite && access != PermissionDeny {
+			errs.add(field, "", "access to %v must be %v, %v or %v, got %q", object, PermissionRead,
+				PermissionWrite, PermissionDeny, access)
+		}
+	}
+}
+
+func validateUser(errs *fieldErrors, user *User, namer fieldNamer) {
+	add := func(name, format string, args ...interface{}) {
+		field, envVar := namer(name)
+		errs.add(field, envVar, format, args...)
+	}
+
+	if user.FirstName == "" {
+		add("firstName", "required")
+	}
+	if user.LastName == "" {
+		add("lastName", "required")
+	}
+
+	if user.EmailAddress == "" {
+		add("email", "required")
+	} else if err := CheckEmail(user.EmailAddress); err != nil {
+		add("email", "%v", err)
+	}
+
+	switch {
+	case user.Password != "":
+		if err := CheckPassword(user.Password, user.EmailAddress); err != nil {
+			add("password", "%v", err)
+		}
+	case !user.GeneratePassword:
+		generateField, generateEnvVar := namer("generatePassword")
+		if generateEnvVar != "" {
+			generateField = fmt.Sprintf("%v (%v)", generateField, generateEnvVar)
+		}
+		add("password", "required unless %v is enabled", generateField)
+	case user.PasswordSecretName == "":
+		add("passwordSecretName", "required when the password is generated")
+	}
+
+	if user.PasswordSecretName != "" {
+		if problems := validation.IsDNS1123Subdomain(user.PasswordSecretName); len(problems) > 0 {
+			add("passwordSecretName", "invalid name %q: %v", user.PasswordSecretName,
+				strings.Join(problems, ", "))
+		}
+	}
+}
+
+// tenantCredentialsSecretName returns the default name of the credentials secret of the first admin user of
+// the organisation with the given name, derived from data.AppConfig.AdminCredentialsSecretName.
+func tenantCredentialsSecretName(orgName string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(orgName) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else if s := b.String(); s != "" && !strings.HasSuffix(s, "-") {
+			b.WriteRune('-')
+		}
+	}
+
+	suffix := strings.Trim(b.String(), "-")
+	if suffix == "" {
+		return AppConfig.AdminCredentialsSecretName
+	}
+
+	name := AppConfig.AdminCredentialsSecretName + "-" + suffix
+	if len(name) > validation.DNS1123SubdomainMaxLength {
+		name = strings.TrimRight(name[:validation.DNS1123SubdomainMaxLength], "-")
+	}
+
+	return name
+}
+
+// validateUniqueOrganisations checks that organisations, and users across organisations, do not clash.
+func validateUniqueOrganisations(errs *fieldErrors) {
+	names := map[string]bool{}
+	cnames := map[string]bool{}
+	emails := map[string]bool{}
+	secrets := map[string]bool{}
+	contexts := map[string]bool{}
+
+	for _, org := range AppConfig.Organisations {
+		if c := org.OperatorContext; c.Enabled {
+			key := c.Namespace + "/" + c.Name
+			if contexts[key] {
+				errs.add("organisations", "", "operator context %q is written more than once in namespace %v",
+					c.Name, c.Namespace)
+			}
+			contexts[key] = true
+		}
+
+		if names[org.Name] {
+			errs.add("organisations", "", "organisation name %q is used more than once", org.Name)
+		}
+		names[org.Name] = true
+
+		if org.Cname != "" && cnames[org.Cname] {
+			errs.add("organisations", "", "cname %q is used more than once", org.Cname)
+		}
+		cnames[org.Cname] = true
+
+		targets := []SecretTarget{org.OperatorSecret, org.PortalSecret}
+
+		users := append(append([]*User{}, org.AdminUsers...), org.Users...)
+		if org.OperatorUser != nil {
+			users = append(users, org.OperatorUser)
+		}
+
+		for _, user := range users {
+			email := strings.ToLower(user.EmailAddress)
+			if email != "" && emails[email] {
+				errs.add("organisations", "", "user email %q is used more than once", user.EmailAddress)
+			}
+			emails[email] = true
+
+			// The credentials secret of the first admin user holds its access key.
+			if (user.Password == "" && user.GeneratePassword) || (len(org.AdminUsers) > 0 && user == org.AdminUsers[0]) {
+				targets = append(targets, SecretTarget{
+					Enabled:    true,
+					Name:       user.PasswordSecretName,
+					Namespaces: []string{AppConfig.TykPodNamespace},
+				})
+			}
+		}
+
+		for _, secret := range targets {
+			if !secret.Enabled || secret.Name == "" {
+				continue
+			}
+
+			for _, ns := range secret.Namespaces {
+				key := ns + "/" + secret.Name
+				if secrets[key] {
+					errs.add("organisations", "", "secret %q is written more than once in namespace %v",
+						secret.Name, ns)
+				}
+				secrets[key] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package data
+
+import (
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// loadPortalPages reads the portal pages described by the YAML and JSON files of dir, in the order of their
+// names. Hidden files and directories are skipped, such as the ones a ConfigMap volume is made of.
+func loadPortalPages(errs *fieldErrors, namer fieldNamer, dir string) []*PortalPage {
+	if dir == "" {
+		return nil
+	}
+
+	field, envVar := namer("portal.pagesDir")
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		errs.add(field, envVar, "failed to read directory, err: %v", err)
+		return nil
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".yaml", ".yml", ".json":
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var pages []*PortalPage
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+
+		info, err := os.Stat(path)
+		if err != nil {
+			errs.add(field, envVar, "failed to read %v, err: %v", name, err)
+			continue
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			errs.add(field, envVar, "failed to read %v, err: %v", name, err)
+			continue
+		}
+
+		page := &PortalPage{}
+		if err = yaml.UnmarshalStrict(raw, page); err != nil {
+			errs.add(field, envVar, "invalid page %v, err: %v", name, err)
+			continue
+		}
+		page.file = name
+		pages = append(pages, page)
+	}
+
+	return pages
+}
+
+// validatePortalPages checks that the pages of org, including its homepage, have a title and distinct
+// slugs, and that at most one of them is the homepage.
+func validatePortalPages(org *Organisation, add func(name, format string, args ...interface{})) {
+	slugs := map[string]bool{}
+	homepages := 0
+	if page := org.PortalHomepage; page != nil {
+		slugs[page.Slug] = true
+		homepages++
+	}
+
+	for _, page := range org.PortalPages {
+		if page.Title == "" {
+			add("portal.pagesDir", "%v: title is required", page.file)
+		}
+		if !strings.HasPrefix(page.Slug, "/") {
+			add("portal.pagesDir", "%v: slug must start with /, got %q", page.file, page.Slug)
+		} else if slugs[page.Slug] {
+			add("portal.pagesDir", "%v: slug %v is used by another page", page.file, page.Slug)
+		}
+		slugs[page.Slug] = true
+
+		if page.Homepage {
+			homepages++
+		}
+	}
+
+	if homepages > 1 {
+		add("portal.pagesDir", "only one page may be the homepage, including portal.homepage, got %d", homepages)
+	}
+}
+
+// validatePortalConfiguration checks the portal settings c, which may be nil.
+func validatePortalConfiguration(c *PortalConfigurationSpec, add func(name, format string, args ...interface{})) {
+	if c == nil {
+		return
+	}
+
+	if c.RedirectOnKeyRequest != nil && *c.RedirectOnKeyRequest && c.RedirectTo == "" {
+		add("portal.configuration.redirectTo", "required when redirecting on key requests")
+	}
+	if c.OAuthUsageLimit != nil && *c.OAuthUsageLimit < -1 {
+		add("portal.configuration.oauthUsageLimit", "must be -1 or more, got %d", *c.OAuthUsageLimit)
+	}
+
+	addresses := []struct {
+		name    string
+		address string
+	}{
+		{"email", c.Email},
+		{"mailFromEmail", c.MailFromEmail},
+	}
+	for _, a := range addresses {
+		if a.address == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(a.address); err != nil {
+			add("portal.configuration."+a.name, "invalid email address %q", a.address)
+		}
+	}
+
+	menus := make([]string, 0, len(c.Menus))
+	for menu := range c.Menus {
+		menus = append(menus, menu)
+	}
+	sort.Strings(menus)
+	for _, menu := range menus {
+		for i, item := range c.Menus[menu] {
+			field := fmt.Sprintf("portal.configuration.menus.%v[%d]", menu, i)
+			if item.Title == "" {
+				add(field+".title", "required")
+			}
+			if item.URL == "" {
+				add(field+".url", "required")
+			}
+		}
+	}
+}
+
+// CatalogueEntry is an API published in the portal catalogue.
+type CatalogueEntry struct {
+	Name             string
+	ShortDescription string
+	LongDescription  string
+	PolicyID         string
+	// Documentation is the OpenAPI or Swagger document of the API, converted to JSON, if any.
+	Documentation      []byte
+	Visible            bool
+	RequireKeyApproval bool
+	KeyRequestFields   []string
+}
+
+// catalogueEntries returns the catalogue entries described by the specs, found at field in the config
+// file, along with their documentation.
+func catalogueEntries(errs *fieldErrors, field string, specs []CatalogueEntrySpec) []*CatalogueEntry {
+	policies := map[string]bool{}
+
+	var entries []*CatalogueEntry
+	for i, spec := range specs {
+		entryField := fmt.Sprintf("%v[%d]", field, i)
+
+		entry := &CatalogueEntry{
+			Name:               spec.Name,
+			ShortDescription:   spec.ShortDescription,
+			LongDescription:    spec.LongDescription,
+			PolicyID:           spec.PolicyID,
+			Visible:            true,
+			RequireKeyApproval: spec.RequireKeyApproval,
+			KeyRequestFields:   spec.KeyRequestFields,
+		}
+		if spec.Visible != nil {
+			entry.Visible = *spec.Visible
+		}
+
+		if entry.Name == "" {
+			errs.add(entryField+".name", "", "required")
+		}
+		switch {
+		case entry.PolicyID == "":
+			errs.add(entryField+".policyId", "", "required")
+		case policies[entry.PolicyID]:
+			errs.add(entryField+".policyId", "", "policy %v is published more than once", entry.PolicyID)
+		}
+		policies[entry.PolicyID] = true
+
+		if spec.DocumentationFile != "" {
+			raw, err := os.ReadFile(spec.DocumentationFile)
+			if err != nil {
+				errs.add(entryField+".documentationFile", "", "failed to read file, err: %v", err)
+			} else if entry.Documentation, err = yaml.YAMLToJSON(raw); err != nil {
+				errs.add(entryField+".documentationFile", "", "invalid YAML or JSON document, err: %v", err)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
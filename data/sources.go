@@ -0,0 +1,161 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"tyk/tyk/bootstrap/k8s"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// fileEnvVarSuffix is appended to the name of a sensitive env var to read its value from a file.
+	fileEnvVarSuffix = "_FILE"
+	// secretRefEnvVarSuffix is appended to the name of a sensitive env var to read its value from a key of
+	// a Secret in the Tyk namespace, given as <secret name>/<key>.
+	secretRefEnvVarSuffix = "_SECRET_REF"
+)
+
+// ValueSource reads a sensitive value from a mounted file or from a key of a Secret in the Tyk namespace,
+// rather than from the configuration itself.
+type ValueSource struct {
+	File         string        `json:"file,omitempty"`
+	SecretKeyRef *SecretKeyRef `json:"secretKeyRef,omitempty"`
+}
+
+// SecretKeyRef selects a key of a Secret.
+type SecretKeyRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+func (r SecretKeyRef) String() string {
+	return r.Name + "/" + r.Key
+}
+
+// sensitiveValue is a setting that may be read from a file or a Secret.
+type sensitiveValue struct {
+	field  string
+	envVar string
+	value  *string
+	// source is the source configured in the bootstrap config file, if any.
+	source *ValueSource
+}
+
+// resolveSensitiveValues sets the given sensitive values from their sources. The env vars <NAME>,
+// <NAME>_FILE and <NAME>_SECRET_REF take precedence over the value and the source of the config file. At
+// most one of them may be set, and the config file may set either a value or a source. Values without an
+// env var are only read from the config file.
+func resolveSensitiveValues(errs *fieldErrors, values []sensitiveValue) {
+	for _, v := range values {
+		fileEnvVar := v.envVar + fileEnvVarSuffix
+		secretRefEnvVar := v.envVar + secretRefEnvVarSuffix
+
+		var set []string
+		for _, name := range []string{v.envVar, fileEnvVar, secretRefEnvVar} {
+			if v.envVar != "" && os.Getenv(name) != "" {
+				set = append(set, name)
+			}
+		}
+
+		if len(set) > 1 {
+			errs.add(v.field, "", "only one of %v may be set", strings.Join(set, ", "))
+			continue
+		}
+
+		source := v.source
+		switch {
+		case len(set) == 0:
+			if source != nil && *v.value != "" {
+				errs.add(v.field, "", "either a value or a source may be set in the config file, not both")
+				continue
+			}
+		case set[0] == v.envVar:
+			*v.value = os.Getenv(v.envVar)
+			continue
+		case set[0] == fileEnvVar:
+			source = &ValueSource{File: os.Getenv(fileEnvVar)}
+		case set[0] == secretRefEnvVar:
+			ref, err := parseSecretKeyRef(os.Getenv(secretRefEnvVar))
+			if err != nil {
+				errs.add(v.field, secretRefEnvVar, "%v", err)
+				continue
+			}
+			source = &ValueSource{SecretKeyRef: ref}
+		}
+
+		if source == nil {
+			continue
+		}
+
+		var sourceEnvVar string
+		if len(set) == 1 {
+			sourceEnvVar = set[0]
+		}
+
+		value, err := source.read()
+		if err != nil {
+			errs.add(v.field, sourceEnvVar, "%v", err)
+			continue
+		}
+		*v.value = value
+	}
+}
+
+func parseSecretKeyRef(raw string) (*SecretKeyRef, error) {
+	parts := strings.Split(raw, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("must be <secret name>/<key>, got %q", raw)
+	}
+
+	return &SecretKeyRef{Name: parts[0], Key: parts[1]}, nil
+}
+
+// read returns the value of the source, without surrounding whitespace.
+func (s *ValueSource) read() (string, error) {
+	switch {
+	case s.File != "" && s.SecretKeyRef != nil:
+		return "", errors.New("either file or secretKeyRef may be set, not both")
+	case s.File != "":
+		raw, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file, err: %v", err)
+		}
+
+		return strings.TrimSpace(string(raw)), nil
+	case s.SecretKeyRef != nil:
+		return readSecretKey(*s.SecretKeyRef)
+	default:
+		return "", errors.New("one of file or secretKeyRef must be set")
+	}
+}
+
+func readSecretKey(ref SecretKeyRef) (string, error) {
+	if ref.Name == "" || ref.Key == "" {
+		return "", fmt.Errorf("secret name and key are required, got %q", ref.String())
+	}
+
+	if AppConfig.TykPodNamespace == "" {
+		return "", errors.New("the Tyk namespace is unknown, so secrets cannot be read")
+	}
+
+	c, err := k8s.NewClientset()
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := c.CoreV1().Secrets(AppConfig.TykPodNamespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %v, err: %v", ref, err)
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %v has no key %v", ref.Name, ref.Key)
+	}
+
+	return strings.TrimSpace(string(value)), nil
+}
@@ -0,0 +1,150 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testEnvVar = "TYK_BOOTSTRAP_TEST_SECRET"
+
+func TestResolveSensitiveValues(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "env")
+	configFile := filepath.Join(dir, "config")
+	if err := os.WriteFile(envFile, []byte("from-env-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configFile, []byte("  from-config-file  "), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		// value and source are set by the config file.
+		value     string
+		source    *ValueSource
+		wantValue string
+		wantErr   string
+	}{
+		{name: "nothing set"},
+		{name: "config value", value: "from-config", wantValue: "from-config"},
+		{name: "config file source", source: &ValueSource{File: configFile}, wantValue: "from-config-file"},
+		{
+			name:      "env var wins over config value",
+			env:       map[string]string{testEnvVar: "from-env"},
+			value:     "from-config",
+			wantValue: "from-env",
+		},
+		{
+			name:      "_FILE wins over config source",
+			env:       map[string]string{testEnvVar + "_FILE": envFile},
+			source:    &ValueSource{File: configFile},
+			wantValue: "from-env-file",
+		},
+		{
+			name:      "_FILE wins over config value",
+			env:       map[string]string{testEnvVar + "_FILE": envFile},
+			value:     "from-config",
+			wantValue: "from-env-file",
+		},
+		{
+			name:    "env var and _FILE",
+			env:     map[string]string{testEnvVar: "from-env", testEnvVar + "_FILE": envFile},
+			wantErr: "only one of " + testEnvVar + ", " + testEnvVar + "_FILE may be set",
+		},
+		{
+			name:    "_FILE and _SECRET_REF",
+			env:     map[string]string{testEnvVar + "_FILE": envFile, testEnvVar + "_SECRET_REF": "tyk/key"},
+			wantErr: "only one of " + testEnvVar + "_FILE, " + testEnvVar + "_SECRET_REF may be set",
+		},
+		{
+			name:    "config value and source",
+			value:   "from-config",
+			source:  &ValueSource{File: configFile},
+			wantErr: "either a value or a source may be set in the config file, not both",
+		},
+		{
+			name:    "config source with file and secret",
+			source:  &ValueSource{File: configFile, SecretKeyRef: &SecretKeyRef{Name: "tyk", Key: "key"}},
+			wantErr: "either file or secretKeyRef may be set, not both",
+		},
+		{
+			name:    "missing _FILE",
+			env:     map[string]string{testEnvVar + "_FILE": filepath.Join(dir, "missing")},
+			wantErr: "field (" + testEnvVar + "_FILE): failed to read file",
+		},
+		{
+			name:    "malformed _SECRET_REF",
+			env:     map[string]string{testEnvVar + "_SECRET_REF": "tyk"},
+			wantErr: "must be <secret name>/<key>",
+		},
+		{
+			name:    "_SECRET_REF without namespace",
+			env:     map[string]string{testEnvVar + "_SECRET_REF": "tyk/key"},
+			wantErr: "the Tyk namespace is unknown",
+		},
+	}
+
+	namespace := AppConfig.TykPodNamespace
+	AppConfig.TykPodNamespace = ""
+	defer func() { AppConfig.TykPodNamespace = namespace }()
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, name := range []string{testEnvVar, testEnvVar + "_FILE", testEnvVar + "_SECRET_REF"} {
+				t.Setenv(name, tc.env[name])
+			}
+
+			value := tc.value
+			var errs fieldErrors
+			resolveSensitiveValues(&errs, []sensitiveValue{
+				{field: "field", envVar: testEnvVar, value: &value, source: tc.source},
+			})
+
+			checkErr(t, errs.err(), tc.wantErr)
+			if tc.wantErr == "" && value != tc.wantValue {
+				t.Errorf("expected value %q, got %q", tc.wantValue, value)
+			}
+		})
+	}
+}
+
+func TestResolveSensitiveValuesWithoutEnvVar(t *testing.T) {
+	t.Setenv(fileEnvVarSuffix, "/nonexistent")
+
+	value := "from-config"
+	var errs fieldErrors
+	resolveSensitiveValues(&errs, []sensitiveValue{{field: "field", value: &value}})
+
+	if err := errs.err(); err != nil || value != "from-config" {
+		t.Errorf("expected the config value to be kept, got %q and error %v", value, err)
+	}
+}
+
+func TestParseSecretKeyRef(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "tyk-secrets/admin-secret", want: "tyk-secrets/admin-secret"},
+		{raw: "tyk-secrets", wantErr: true},
+		{raw: "tyk-secrets/", wantErr: true},
+		{raw: "/admin-secret", wantErr: true},
+		{raw: "a/b/c", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.raw, func(t *testing.T) {
+			ref, err := parseSecretKeyRef(tc.raw)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.wantErr && ref.String() != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, ref)
+			}
+		})
+	}
+}
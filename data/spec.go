@@ -0,0 +1,515 @@
+package data
+
+import (
+	"fmt"
+	"os"
+	"tyk/tyk/bootstrap/constants"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Spec is the declarative bootstrap configuration. It is read from a YAML or JSON file, typically mounted
+// from a ConfigMap, and applied on top of the defaults of AppConfig. Env vars take precedence over it.
+type Spec struct {
+	Dashboard    DashboardSpec    `json:"dashboard"`
+	Organisation OrganisationSpec `json:"organisation"`
+	AdminUser    UserSpec         `json:"adminUser"`
+	Secrets      SecretsSpec      `json:"secrets"`
+	Portal       PortalSpec       `json:"portal"`
+	// EnterprisePortal configures the Tyk Enterprise Developer Portal, bootstrapped instead of the classic
+	// portal if portal.mode is enterprise.
+	EnterprisePortal EnterprisePortalSpec `json:"enterprisePortal"`
+	Hooks            HooksSpec            `json:"hooks"`
+	// Users and UserGroups are created in the organisation configured above.
+	Users      []DashboardUserSpec `json:"users,omitempty"`
+	UserGroups []UserGroupSpec     `json:"userGroups,omitempty"`
+	// Organisations are bootstrapped after the organisation configured above, if any, in order.
+	Organisations []TenantSpec `json:"organisations,omitempty"`
+}
+
+type DashboardSpec struct {
+	Enabled            *bool        `json:"enabled,omitempty"`
+	URL                string       `json:"url,omitempty"`
+	Protocol           string       `json:"protocol,omitempty"`
+	InsecureSkipVerify *bool        `json:"insecureSkipVerify,omitempty"`
+	CACert             string       `json:"caCert,omitempty"`
+	CACertFrom         *ValueSource `json:"caCertFrom,omitempty"`
+	AdminSecret        string       `json:"adminSecret,omitempty"`
+	AdminSecretFrom    *ValueSource `json:"adminSecretFrom,omitempty"`
+	License            string       `json:"license,omitempty"`
+	LicenseFrom        *ValueSource `json:"licenseFrom,omitempty"`
+	DeploymentName     string       `json:"deploymentName,omitempty"`
+}
+
+type OrganisationSpec struct {
+	Name          string `json:"name,omitempty"`
+	Cname         string `json:"cname,omitempty"`
+	AdoptExisting *bool  `json:"adoptExisting,omitempty"`
+}
+
+// TenantSpec describes one of several organisations hosted on the same Dashboard, with its own admin users,
+// secrets and portal.
+type TenantSpec struct {
+	OrganisationSpec `json:",inline"`
+	AdminUsers       []UserSpec          `json:"adminUsers,omitempty"`
+	Users            []DashboardUserSpec `json:"users,omitempty"`
+	UserGroups       []UserGroupSpec     `json:"userGroups,omitempty"`
+	OperatorSecret   OperatorSecretSpec  `json:"operatorSecret"`
+	PortalSecret     SecretSpec          `json:"portalSecret"`
+	Portal           PortalSpec          `json:"portal"`
+}
+
+// UserSpec describes a Dashboard user. If GeneratePassword is set and no password is configured, a random
+// password is generated and stored in the PasswordSecretName Secret.
+type UserSpec struct {
+	FirstName          string       `json:"firstName,omitempty"`
+	LastName           string       `json:"lastName,omitempty"`
+	EmailAddress       string       `json:"email,omitempty"`
+	Password           string       `json:"password,omitempty"`
+	PasswordFrom       *ValueSource `json:"passwordFrom,omitempty"`
+	GeneratePassword   *bool        `json:"generatePassword,omitempty"`
+	PasswordSecretName string       `json:"passwordSecretName,omitempty"`
+}
+
+// DashboardUserSpec describes a user that is not an admin, whose permissions are either set explicitly or
+// inherited from its user group.
+type DashboardUserSpec struct {
+	UserSpec    `json:",inline"`
+	Permissions map[string]string `json:"permissions,omitempty"`
+	Group       string            `json:"group,omitempty"`
+}
+
+type UserGroupSpec struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Permissions map[string]string `json:"permissions"`
+}
+
+type SecretsSpec struct {
+	Operator OperatorSecretSpec `json:"operator"`
+	Portal   SecretSpec         `json:"portal"`
+	// OwnerReferences makes the Tyk Dashboard Deployment the owner of the generated Secrets in its namespace,
+	// so that they are garbage collected along with it.
+	OwnerReferences *bool `json:"ownerReferences,omitempty"`
+}
+
+type SecretSpec struct {
+	Enabled *bool  `json:"enabled,omitempty"`
+	Name    string `json:"name,omitempty"`
+	// Namespace, Namespaces and the namespaces matching NamespaceSelector are the namespaces the Secret is
+	// written to. They default to the namespace Tyk is deployed to.
+	Namespace         string   `json:"namespace,omitempty"`
+	Namespaces        []string `json:"namespaces,omitempty"`
+	NamespaceSelector string   `json:"namespaceSelector,omitempty"`
+}
+
+// namespaces returns the namespaces the Secret is written to, besides the ones matching its selector.
+func (s *SecretSpec) namespaces() []string {
+	if s.Namespace == "" {
+		return s.Namespaces
+	}
+
+	return append([]string{s.Namespace}, s.Namespaces...)
+}
+
+// target returns the name and namespaces of the SecretTarget described by the spec.
+func (s *SecretSpec) target() SecretTarget {
+	return SecretTarget{
+		Name:              s.Name,
+		Namespaces:        s.namespaces(),
+		NamespaceSelector: s.NamespaceSelector,
+	}
+}
+
+type OperatorSecretSpec struct {
+	SecretSpec `json:",inline"`
+	// User overrides the defaults of the service user whose access key is stored in the secret.
+	User *OperatorUserSpec `json:"user,omitempty"`
+	// ExtraData holds additional keys of the secret, e.g. other settings of Tyk Operator.
+	ExtraData map[string]string `json:"extraData,omitempty"`
+	// Context describes an OperatorContext referencing the secret.
+	Context *OperatorContextSpec `json:"context,omitempty"`
+}
+
+// OperatorContextSpec describes an OperatorContext custom resource of Tyk Operator. Its name defaults to the
+// name of the operator secret and its namespace to the namespace Tyk is deployed to.
+type OperatorContextSpec struct {
+	Enabled   *bool  `json:"enabled,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type OperatorUserSpec struct {
+	FirstName    string            `json:"firstName,omitempty"`
+	LastName     string            `json:"lastName,omitempty"`
+	EmailAddress string            `json:"email,omitempty"`
+	Permissions  map[string]string `json:"permissions,omitempty"`
+}
+
+type PortalSpec struct {
+	Bootstrap *bool `json:"bootstrap,omitempty"`
+	// Mode is the portal bootstrapped for all organisations, classic or enterprise. It is only set at the top
+	// level of the config file.
+	Mode     string      `json:"mode,omitempty"`
+	Homepage *PortalPage `json:"homepage,omitempty"`
+	// PagesDir is a directory of YAML or JSON files, e.g. mounted from a ConfigMap, each describing a page.
+	PagesDir string `json:"pagesDir,omitempty"`
+	// Catalogue lists the APIs published in the catalogue of the portal.
+	Catalogue []CatalogueEntrySpec `json:"catalogue,omitempty"`
+	// Configuration holds the settings of the portal. Settings left unset keep their current value, or the
+	// Dashboard default when the portal is created.
+	Configuration *PortalConfigurationSpec `json:"configuration,omitempty"`
+}
+
+// PortalConfigurationSpec holds the settings of a Tyk Classic Portal.
+type PortalConfigurationSpec struct {
+	SignupDisabled *bool `json:"signupDisabled,omitempty"`
+	LoginDisabled  *bool `json:"loginDisabled,omitempty"`
+	// SignupFields and KeyRequestFields are the extra fields developers fill in when signing up and requesting
+	// a key.
+	SignupFields     []string `json:"signupFields,omitempty"`
+	KeyRequestFields []string `json:"keyRequestFields,omitempty"`
+	// AutoApproveKeyRequests issues keys as soon as they are requested, instead of waiting for an admin's
+	// approval.
+	AutoApproveKeyRequests *bool `json:"autoApproveKeyRequests,omitempty"`
+	// RedirectOnKeyRequest redirects developers to RedirectTo instead of issuing the key they request.
+	RedirectOnKeyRequest *bool  `json:"redirectOnKeyRequest,omitempty"`
+	RedirectTo           string `json:"redirectTo,omitempty"`
+	// OAuthUsageLimit is the number of OAuth clients a developer may register, -1 for no limit.
+	OAuthUsageLimit *int `json:"oauthUsageLimit,omitempty"`
+	// Email is the address notified of key requests.
+	Email          string                `json:"email,omitempty"`
+	MailFromName   string                `json:"mailFromName,omitempty"`
+	MailFromEmail  string                `json:"mailFromEmail,omitempty"`
+	EmailTemplates *EmailTemplatesSpec   `json:"emailTemplates,omitempty"`
+	CustomCSS      *string               `json:"customCSS,omitempty"`
+	CustomJS       *string               `json:"customJS,omitempty"`
+	Menus          map[string][]MenuItem `json:"menus,omitempty"`
+}
+
+// EmailTemplatesSpec holds the emails sent to developers by the portal.
+type EmailTemplatesSpec struct {
+	Welcome       *EmailTemplateSpec `json:"welcome,omitempty"`
+	Key           *EmailTemplateSpec `json:"key,omitempty"`
+	ResetPassword *EmailTemplateSpec `json:"resetPassword,omitempty"`
+}
+
+// EmailTemplateSpec is the content of an email sent by the portal.
+type EmailTemplateSpec struct {
+	Enabled *bool  `json:"enabled,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body,omitempty"`
+	SignOff string `json:"signOff,omitempty"`
+	// HideTokenData leaves the key out of the key email.
+	HideTokenData *bool `json:"hideTokenData,omitempty"`
+}
+
+// MenuItem is a link of a portal menu.
+type MenuItem struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// CatalogueEntrySpec describes an API published in the portal catalogue, whose keys are issued by a policy.
+type CatalogueEntrySpec struct {
+	Name             string `json:"name"`
+	ShortDescription string `json:"shortDescription,omitempty"`
+	LongDescription  string `json:"longDescription,omitempty"`
+	PolicyID         string `json:"policyId"`
+	// DocumentationFile is the path of an OpenAPI or Swagger document, in YAML or JSON.
+	DocumentationFile string `json:"documentationFile,omitempty"`
+	// Visible shows the API in the catalogue, which is the default.
+	Visible *bool `json:"visible,omitempty"`
+	// RequireKeyApproval makes key requests wait for an admin's approval.
+	RequireKeyApproval bool `json:"requireKeyApproval,omitempty"`
+	// KeyRequestFields are the fields developers fill in when requesting a key.
+	KeyRequestFields []string `json:"keyRequestFields,omitempty"`
+}
+
+// PortalPage is the content of a Tyk Classic Portal page.
+type PortalPage struct {
+	Title        string            `json:"title"`
+	Slug         string            `json:"slug"`
+	TemplateName string            `json:"templateName,omitempty"`
+	Fields       map[string]string `json:"fields,omitempty"`
+	// Homepage makes the page the homepage of the portal. It is implied for portal.homepage.
+	Homepage bool `json:"homepage,omitempty"`
+
+	// file is the file the page is read from, if any.
+	file string
+}
+
+// EnterprisePortalSpec describes the Tyk Enterprise Developer Portal and its first admin user. The admin
+// defaults to the name and email of the Dashboard admin user. If no password is configured, the password
+// stored in the CredentialsSecretName Secret is reused, or a random one is generated.
+type EnterprisePortalSpec struct {
+	URL                   string                    `json:"url,omitempty"`
+	HealthTimeout         *metav1.Duration          `json:"healthTimeout,omitempty"`
+	InsecureSkipVerify    *bool                     `json:"insecureSkipVerify,omitempty"`
+	CACert                string                    `json:"caCert,omitempty"`
+	CACertFrom            *ValueSource              `json:"caCertFrom,omitempty"`
+	Admin                 EnterprisePortalAdminSpec `json:"admin"`
+	CredentialsSecretName string                    `json:"credentialsSecretName,omitempty"`
+}
+
+type EnterprisePortalAdminSpec struct {
+	FirstName    string       `json:"firstName,omitempty"`
+	LastName     string       `json:"lastName,omitempty"`
+	EmailAddress string       `json:"email,omitempty"`
+	Password     string       `json:"password,omitempty"`
+	PasswordFrom *ValueSource `json:"passwordFrom,omitempty"`
+}
+
+type HooksSpec struct {
+	PostInstall PostInstallHookSpec `json:"postInstall"`
+}
+
+type PostInstallHookSpec struct {
+	Readiness              ReadinessSpec    `json:"readiness"`
+	DashboardHealthTimeout *metav1.Duration `json:"dashboardHealthTimeout,omitempty"`
+	// DashboardRolloutTimeout is how long the rollout of the Dashboard is waited for, once it is restarted
+	// to apply the portal cnames.
+	DashboardRolloutTimeout *metav1.Duration `json:"dashboardRolloutTimeout,omitempty"`
+}
+
+// ReadinessSpec selects the workloads that must be ready before bootstrapping. Empty, but set, podSelectors
+// and deploymentSelectors lists disable the default selection of the Redis Pods and of the Tyk Dashboard
+// Deployment.
+type ReadinessSpec struct {
+	PodSelectors        []string         `json:"podSelectors"`
+	DeploymentSelectors []string         `json:"deploymentSelectors"`
+	Deployments         []string         `json:"deployments,omitempty"`
+	StatefulSets        []string         `json:"statefulSets,omitempty"`
+	Timeout             *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// LoadSpec reads the bootstrap configuration file at path. Unknown fields and values of the wrong type are
+// rejected.
+func LoadSpec(path string) (*Spec, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bootstrap config file %v, err: %v", path, err)
+	}
+
+	spec := &Spec{}
+	if err = yaml.UnmarshalStrict(raw, spec); err != nil {
+		return nil, fmt.Errorf("invalid bootstrap config file %v, err: %v", path, err)
+	}
+
+	return spec, nil
+}
+
+// apply sets the fields of c that are configured by the spec.
+func (s *Spec) apply(c *AppArguments) {
+	stringFields := []struct {
+		value string
+		field *string
+	}{
+		{s.Dashboard.URL, &c.DashboardUrl},
+		{s.Dashboard.Protocol, &c.DashboardProto},
+		{s.Dashboard.AdminSecret, &c.TykAdminSecret},
+		{s.Dashboard.License, &c.DashBoardLicense},
+		{s.Dashboard.CACert, &c.DashboardCACert},
+		{s.Dashboard.DeploymentName, &c.DashboardDeploymentName},
+		{s.Portal.PagesDir, &c.PortalPagesDir},
+		{s.Portal.Mode, &c.PortalMode},
+		{s.EnterprisePortal.URL, &c.EnterprisePortalUrl},
+		{s.EnterprisePortal.CACert, &c.EnterprisePortalCACert},
+		{s.EnterprisePortal.Admin.FirstName, &c.EnterprisePortalAdminFirstName},
+		{s.EnterprisePortal.Admin.LastName, &c.EnterprisePortalAdminLastName},
+		{s.EnterprisePortal.Admin.EmailAddress, &c.EnterprisePortalAdminEmailAddress},
+		{s.EnterprisePortal.Admin.Password, &c.EnterprisePortalAdminPassword},
+		{s.EnterprisePortal.CredentialsSecretName, &c.EnterprisePortalCredentialsSecretName},
+		{s.Organisation.Name, &c.CurrentOrgName},
+		{s.Organisation.Cname, &c.Cname},
+		{s.AdminUser.FirstName, &c.TykAdminFirstName},
+		{s.AdminUser.LastName, &c.TykAdminLastName},
+		{s.AdminUser.EmailAddress, &c.TykAdminEmailAddress},
+		{s.AdminUser.Password, &c.TykAdminPassword},
+		{s.AdminUser.PasswordSecretName, &c.AdminCredentialsSecretName},
+		{s.Secrets.Operator.Name, &c.OperatorSecretName},
+		{s.Secrets.Portal.Name, &c.DeveloperPortalSecretName},
+		{s.Secrets.Operator.NamespaceSelector, &c.OperatorSecretNamespaceSelector},
+		{s.Secrets.Portal.NamespaceSelector, &c.DeveloperPortalSecretNamespaceSelector},
+	}
+	for _, f := range stringFields {
+		if f.value != "" {
+			*f.field = f.value
+		}
+	}
+
+	boolFields := []struct {
+		value *bool
+		field *bool
+	}{
+		{s.Dashboard.Enabled, &c.IsDashboardEnabled},
+		{s.Dashboard.InsecureSkipVerify, &c.DashboardInsecureSkipVerify},
+		{s.EnterprisePortal.InsecureSkipVerify, &c.EnterprisePortalInsecureSkipVerify},
+		{s.Organisation.AdoptExisting, &c.AdoptExistingOrg},
+		{s.Secrets.Operator.Enabled, &c.OperatorSecretEnabled},
+		{s.Secrets.Portal.Enabled, &c.DeveloperPortalSecretEnabled},
+		{s.Portal.Bootstrap, &c.BootstrapPortal},
+		{s.AdminUser.GeneratePassword, &c.GenerateAdminPassword},
+		{s.Secrets.OwnerReferences, &c.SecretOwnerReferences},
+	}
+	for _, f := range boolFields {
+		if f.value != nil {
+			*f.field = *f.value
+		}
+	}
+
+	c.OperatorSecretExtraData = s.Secrets.Operator.ExtraData
+	if ctx := s.Secrets.Operator.Context; ctx != nil {
+		if ctx.Enabled != nil {
+			c.OperatorContextEnabled = *ctx.Enabled
+		}
+		c.OperatorContextName = ctx.Name
+		c.OperatorContextNamespace = ctx.Namespace
+	}
+	if namespaces := s.Secrets.Operator.namespaces(); len(namespaces) > 0 {
+		c.OperatorSecretNamespaces = namespaces
+	}
+	if namespaces := s.Secrets.Portal.namespaces(); len(namespaces) > 0 {
+		c.DeveloperPortalSecretNamespaces = namespaces
+	}
+
+	readiness := s.Hooks.PostInstall.Readiness
+	c.ReadinessPodSelectors = readiness.PodSelectors
+	c.ReadinessDeploymentSelectors = readiness.DeploymentSelectors
+	c.ReadinessDeployments = readiness.Deployments
+	c.ReadinessStatefulSets = readiness.StatefulSets
+	if readiness.Timeout != nil {
+		c.ReadinessTimeout = readiness.Timeout.Duration
+	}
+
+	if s.Hooks.PostInstall.DashboardHealthTimeout != nil {
+		c.DashboardHealthTimeout = s.Hooks.PostInstall.DashboardHealthTimeout.Duration
+	}
+	if s.Hooks.PostInstall.DashboardRolloutTimeout != nil {
+		c.DashboardRolloutTimeout = s.Hooks.PostInstall.DashboardRolloutTimeout.Duration
+	}
+	if s.EnterprisePortal.HealthTimeout != nil {
+		c.EnterprisePortalHealthTimeout = s.EnterprisePortal.HealthTimeout.Duration
+	}
+
+	c.PortalHomepage = s.Portal.Homepage
+	c.PortalConfiguration = s.Portal.Configuration
+}
+
+// sensitiveValues returns the settings of c that may be read from a file or a Secret, along with the
+// sources configured by the spec. s may be nil.
+func (s *Spec) sensitiveValues(c *AppArguments) []sensitiveValue {
+	if s == nil {
+		s = &Spec{}
+	}
+
+	return []sensitiveValue{
+		{"dashboard.adminSecret", constants.TykAdminSecretEnvVar, &c.TykAdminSecret, s.Dashboard.AdminSecretFrom},
+		{"adminUser.password", constants.TykAdminPasswordEnvVar, &c.TykAdminPassword, s.AdminUser.PasswordFrom},
+		{"dashboard.license", constants.TykDbLicensekeyEnvVar, &c.DashBoardLicense, s.Dashboard.LicenseFrom},
+		{"dashboard.caCert", constants.TykDashboardCACertEnvVar, &c.DashboardCACert, s.Dashboard.CACertFrom},
+		{"enterprisePortal.admin.password", constants.EnterprisePortalAdminPasswordEnvVar,
+			&c.EnterprisePortalAdminPassword, s.EnterprisePortal.Admin.PasswordFrom},
+		{"enterprisePortal.caCert", constants.EnterprisePortalCACertEnvVar, &c.EnterprisePortalCACert,
+			s.EnterprisePortal.CACertFrom},
+	}
+}
+
+// organisation returns the organisation described by the tenant spec, whose settings are found at prefix
+// in the config file. Admin user passwords are read from their sources.
+func (t *TenantSpec) organisation(errs *fieldErrors, prefix string) *Organisation {
+	org := &Organisation{
+		Name:                    t.Name,
+		Cname:                   t.Cname,
+		OperatorSecret:          t.OperatorSecret.target(),
+		OperatorSecretExtraData: t.OperatorSecret.ExtraData,
+		PortalSecret:            t.PortalSecret.target(),
+		PortalHomepage:          t.Portal.Homepage,
+		PortalConfiguration:     t.Portal.Configuration,
+	}
+
+	boolFields := []struct {
+		value *bool
+		field *bool
+	}{
+		{t.AdoptExisting, &org.AdoptExisting},
+		{t.OperatorSecret.Enabled, &org.OperatorSecret.Enabled},
+		{t.PortalSecret.Enabled, &org.PortalSecret.Enabled},
+		{t.Portal.Bootstrap, &org.BootstrapPortal},
+	}
+	for _, f := range boolFields {
+		if f.value != nil {
+			*f.field = *f.value
+		}
+	}
+
+	for i, u := range t.AdminUsers {
+		user := u.user(errs, fmt.Sprintf("%v.adminUsers[%d]", prefix, i))
+		user.Permissions = AdminPermissions()
+		org.AdminUsers = append(org.AdminUsers, user)
+	}
+
+	if ctx := t.OperatorSecret.Context; ctx != nil {
+		org.OperatorContext = OperatorContextTarget{Name: ctx.Name, Namespace: ctx.Namespace}
+		if ctx.Enabled != nil {
+			org.OperatorContext.Enabled = *ctx.Enabled
+		}
+	}
+
+	org.Users, org.UserGroups = usersAndGroups(errs, prefix+".", t.Users, t.UserGroups)
+	org.PortalCatalogue = catalogueEntries(errs, prefix+".portal.catalogue", t.Portal.Catalogue)
+	if t.Portal.Mode != "" {
+		errs.add(prefix+".portal.mode", "", "the portal mode of all organisations is set by portal.mode")
+	}
+	if org.OperatorSecret.Enabled {
+		org.OperatorUser = operatorUser(org.Name, t.OperatorSecret.User)
+	}
+
+	return org
+}
+
+// user returns the user described by the spec, whose settings are found at field in the config file. The
+// password is read from its source.
+func (u *UserSpec) user(errs *fieldErrors, field string) *User {
+	user := &User{
+		FirstName:          u.FirstName,
+		LastName:           u.LastName,
+		EmailAddress:       u.EmailAddress,
+		Password:           u.Password,
+		PasswordSecretName: u.PasswordSecretName,
+	}
+	if u.GeneratePassword != nil {
+		user.GeneratePassword = *u.GeneratePassword
+	}
+
+	resolveSensitiveValues(errs, []sensitiveValue{
+		{field + ".password", "", &user.Password, u.PasswordFrom},
+	})
+
+	return user
+}
+
+// usersAndGroups returns the users and user groups described by the specs, whose settings are found after
+// prefix in the config file.
+func usersAndGroups(errs *fieldErrors, prefix string, users []DashboardUserSpec,
+	groups []UserGroupSpec) ([]*User, []*UserGroup) {
+	var orgUsers []*User
+	for i, u := range users {
+		user := u.user(errs, fmt.Sprintf("%vusers[%d]", prefix, i))
+		user.Permissions = u.Permissions
+		user.Group = u.Group
+		orgUsers = append(orgUsers, user)
+	}
+
+	var orgGroups []*UserGroup
+	for _, g := range groups {
+		orgGroups = append(orgGroups, &UserGroup{
+			Name:        g.Name,
+			Description: g.Description,
+			Permissions: g.Permissions,
+		})
+	}
+
+	return orgUsers, orgGroups
+}
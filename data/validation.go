@@ -0,0 +1,188 @@
+package data
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"strings"
+	"tyk/tyk/bootstrap/constants"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// fieldErrors collects the problems found in the configuration, so that they can all be reported at once.
+type fieldErrors []string
+
+// add records a problem with the given field. The env var overriding the field is mentioned, if any.
+func (e *fieldErrors) add(field, envVar, format string, args ...interface{}) {
+	if envVar != "" {
+		field = fmt.Sprintf("%v (%v)", field, envVar)
+	}
+
+	*e = append(*e, fmt.Sprintf("%v: %v", field, fmt.Sprintf(format, args...)))
+}
+
+// addErr records err, unless it is nil.
+func (e *fieldErrors) addErr(err error) {
+	if err != nil {
+		*e = append(*e, err.Error())
+	}
+}
+
+func (e fieldErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("invalid bootstrap configuration:\n- %v", strings.Join(e, "\n- "))
+}
+
+// validatePostInstall checks the configuration of the post install hook, once the config file and env vars
+// are applied. Organisations are validated while they are built by initOrganisations.
+func validatePostInstall(errs *fieldErrors) {
+	if AppConfig.TykAdminSecret == "" {
+		errs.add("dashboard.adminSecret", constants.TykAdminSecretEnvVar, "required")
+	} else if err := CheckAdminSecret(AppConfig.TykAdminSecret); err != nil {
+		errs.add("dashboard.adminSecret", constants.TykAdminSecretEnvVar, "%v", err)
+	}
+
+	switch {
+	case AppConfig.DashboardUrl != "":
+		u, err := url.Parse(AppConfig.DashboardUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.add("dashboard.url", constants.TykDashboardUrlEnvVar,
+				"must be an absolute http or https URL, got %q", AppConfig.DashboardUrl)
+		}
+	case !AppConfig.IsDashboardEnabled:
+		errs.add("dashboard.url", constants.TykDashboardUrlEnvVar,
+			"required unless the Dashboard Service is discovered, which needs dashboard.enabled (%v)",
+			constants.DashboardEnabledEnvVar)
+	case AppConfig.DashboardProto != "http" && AppConfig.DashboardProto != "https":
+		errs.add("dashboard.protocol", constants.TykDashboardProtoEnvVar,
+			"must be http or https, got %q", AppConfig.DashboardProto)
+	}
+
+	AppConfig.DashboardRootCAs = parseCACert(errs, "dashboard.caCert", constants.TykDashboardCACertEnvVar,
+		AppConfig.DashboardCACert)
+
+	for _, selector := range AppConfig.ReadinessPodSelectors {
+		if _, err := labels.Parse(selector); err != nil {
+			errs.add("hooks.postInstall.readiness.podSelectors", constants.ReadinessPodSelectorsEnvVar,
+				"invalid label selector %q, err: %v", selector, err)
+		}
+	}
+	for _, selector := range AppConfig.ReadinessDeploymentSelectors {
+		if _, err := labels.Parse(selector); err != nil {
+			errs.add("hooks.postInstall.readiness.deploymentSelectors", constants.ReadinessDeploymentSelectorsEnvVar,
+				"invalid label selector %q, err: %v", selector, err)
+		}
+	}
+
+	if AppConfig.ReadinessTimeout <= 0 {
+		errs.add("hooks.postInstall.readiness.timeout", constants.ReadinessTimeoutEnvVar, "must be positive")
+	}
+	if AppConfig.DashboardHealthTimeout <= 0 {
+		errs.add("hooks.postInstall.dashboardHealthTimeout", constants.DashboardHealthTimeoutEnvVar,
+			"must be positive")
+	}
+	if AppConfig.DashboardRolloutTimeout <= 0 {
+		errs.add("hooks.postInstall.dashboardRolloutTimeout", constants.DashboardRolloutTimeoutEnvVar,
+			"must be positive")
+	}
+}
+
+// validateEnterprisePortal checks the portal mode and, if the Enterprise Developer Portal is bootstrapped for
+// any organisation, its settings. Its admin user defaults to the name and email of the Dashboard admin user.
+func validateEnterprisePortal(errs *fieldErrors) {
+	switch AppConfig.PortalMode {
+	case constants.PortalModeClassic:
+		return
+	case constants.PortalModeEnterprise:
+	default:
+		errs.add("portal.mode", constants.PortalModeEnvVar, "must be %v or %v, got %q",
+			constants.PortalModeClassic, constants.PortalModeEnterprise, AppConfig.PortalMode)
+		return
+	}
+
+	bootstrapped := false
+	for _, org := range AppConfig.Organisations {
+		bootstrapped = bootstrapped || org.BootstrapPortal
+	}
+	if !bootstrapped {
+		return
+	}
+
+	defaults := []struct {
+		value string
+		field *string
+	}{
+		{AppConfig.TykAdminFirstName, &AppConfig.EnterprisePortalAdminFirstName},
+		{AppConfig.TykAdminLastName, &AppConfig.EnterprisePortalAdminLastName},
+		{AppConfig.TykAdminEmailAddress, &AppConfig.EnterprisePortalAdminEmailAddress},
+	}
+	for _, d := range defaults {
+		if *d.field == "" {
+			*d.field = d.value
+		}
+	}
+
+	if AppConfig.EnterprisePortalUrl == "" {
+		errs.add("enterprisePortal.url", constants.EnterprisePortalUrlEnvVar, "required in %v portal mode",
+			constants.PortalModeEnterprise)
+	} else if u, err := url.Parse(AppConfig.EnterprisePortalUrl); err != nil ||
+		(u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs.add("enterprisePortal.url", constants.EnterprisePortalUrlEnvVar,
+			"must be an absolute http or https URL, got %q", AppConfig.EnterprisePortalUrl)
+	}
+
+	AppConfig.EnterprisePortalRootCAs = parseCACert(errs, "enterprisePortal.caCert",
+		constants.EnterprisePortalCACertEnvVar, AppConfig.EnterprisePortalCACert)
+
+	if AppConfig.EnterprisePortalHealthTimeout <= 0 {
+		errs.add("enterprisePortal.healthTimeout", constants.EnterprisePortalHealthTimeoutEnvVar,
+			"must be positive")
+	}
+
+	email := AppConfig.EnterprisePortalAdminEmailAddress
+	if email == "" {
+		errs.add("enterprisePortal.admin.email", constants.EnterprisePortalAdminEmailEnvVar,
+			"required unless the Dashboard admin user is configured")
+	} else if err := CheckEmail(email); err != nil {
+		errs.add("enterprisePortal.admin.email", constants.EnterprisePortalAdminEmailEnvVar, "%v", err)
+	}
+
+	if password := AppConfig.EnterprisePortalAdminPassword; password != "" {
+		if err := CheckPassword(password, email); err != nil {
+			errs.add("enterprisePortal.admin.password", constants.EnterprisePortalAdminPasswordEnvVar, "%v", err)
+		}
+	}
+
+	name := AppConfig.EnterprisePortalCredentialsSecretName
+	if name == "" {
+		errs.add("enterprisePortal.credentialsSecretName", constants.EnterprisePortalCredentialsSecretNameEnvVar,
+			"required")
+	} else if problems := validation.IsDNS1123Subdomain(name); len(problems) > 0 {
+		errs.add("enterprisePortal.credentialsSecretName", constants.EnterprisePortalCredentialsSecretNameEnvVar,
+			"invalid name %q: %v", name, strings.Join(problems, ", "))
+	}
+}
+
+// parseCACert returns the system roots along with the certificates of the PEM encoded CA bundle set by field,
+// or nil if the bundle is empty.
+func parseCACert(errs *fieldErrors, field, envVar, caCert string) *x509.CertPool {
+	if caCert == "" {
+		return nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM([]byte(caCert)) {
+		errs.add(field, envVar, "no PEM encoded certificates found")
+		return nil
+	}
+
+	return pool
+}
@@ -0,0 +1,48 @@
+package data
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFieldErrors(t *testing.T) {
+	var errs fieldErrors
+	if err := errs.err(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	errs.add("dashboard.adminSecret", "TYK_ADMIN_SECRET", "required")
+	errs.add("organizations[0].name", "", "must be at most %d characters long", 63)
+	errs.addErr(nil)
+	errs.addErr(os.ErrNotExist)
+
+	want := strings.Join([]string{
+		"invalid bootstrap configuration:",
+		"- dashboard.adminSecret (TYK_ADMIN_SECRET): required",
+		"- organizations[0].name: must be at most 63 characters long",
+		"- " + os.ErrNotExist.Error(),
+	}, "\n")
+	if err := errs.err(); err == nil || err.Error() != want {
+		t.Errorf("expected\n%v\ngot\n%v", want, err)
+	}
+}
+
+func TestValidateUserReportsEveryProblem(t *testing.T) {
+	var errs fieldErrors
+	validateUser(&errs, &User{EmailAddress: "admin", Password: "short"}, prefixNamer("organizations[0].admin"))
+
+	for _, want := range []string{
+		"organizations[0].admin.firstName: required",
+		"organizations[0].admin.lastName: required",
+		"organizations[0].admin.email: must be a plain email address",
+		"organizations[0].admin.password: must be at least 12 characters long",
+	} {
+		if !strings.Contains(errs.err().Error(), want) {
+			t.Errorf("expected %q to be reported, got %v", want, errs.err())
+		}
+	}
+	if len(errs) != 4 {
+		t.Errorf("expected 4 problems, got %d: %v", len(errs), errs)
+	}
+}
@@ -0,0 +1,95 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/data"
+	"tyk/tyk/bootstrap/k8s"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EnsureUserPassword generates the password of user if it is not configured and password generation is
+// enabled. The generated password is stored in the user.PasswordSecretName Secret before the user is
+// created, and reused by later runs so that the user's password stays stable.
+func EnsureUserPassword(ctx context.Context, user *data.User) error {
+	if user.Password != "" || !user.GeneratePassword {
+		return nil
+	}
+
+	clientset, err := k8s.NewClientset()
+	if err != nil {
+		return err
+	}
+
+	secrets := clientset.CoreV1().Secrets(data.AppConfig.TykPodNamespace)
+	name := user.PasswordSecretName
+	email := user.EmailAddress
+
+	secret, err := secrets.Get(ctx, name, v1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return fmt.Errorf("failed to get credentials secret %v, err: %v", name, err)
+	case string(secret.Data[constants.TykAdminEmailEnvVar]) == email &&
+		len(secret.Data[constants.TykAdminPasswordEnvVar]) > 0:
+		fmt.Printf("Reusing the password of %v stored in secret %v\n", email, name)
+		user.Password = string(secret.Data[constants.TykAdminPasswordEnvVar])
+
+		return nil
+	}
+
+	password, err := data.GeneratePassword(email)
+	if err != nil {
+		return err
+	}
+
+	err = applyCredentialsSecret(ctx, name, email, map[string][]byte{
+		constants.TykAdminPasswordEnvVar: []byte(password),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store generated password of %v in secret %v, err: %v", email, name, err)
+	}
+
+	fmt.Printf("Generated the password of %v and stored it in secret %v\n", email, name)
+	user.Password = password
+
+	return nil
+}
+
+// applyCredentialsSecret creates or updates the credentials secret name of the user with the given email in
+// the namespace Tyk is deployed to, setting values. The other keys stored for the same user are kept, while
+// the keys stored for another user are dropped.
+func applyCredentialsSecret(ctx context.Context, name, email string, values map[string][]byte) error {
+	clientset, err := k8s.NewClientset()
+	if err != nil {
+		return err
+	}
+
+	secretData := map[string][]byte{}
+	secret, err := clientset.CoreV1().Secrets(data.AppConfig.TykPodNamespace).Get(ctx, name, v1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return fmt.Errorf("failed to get credentials secret %v, err: %v", name, err)
+	case string(secret.Data[constants.TykAdminEmailEnvVar]) == email:
+		for key, value := range secret.Data {
+			secretData[key] = value
+		}
+	}
+
+	for key, value := range values {
+		secretData[key] = value
+	}
+	secretData[constants.TykAdminEmailEnvVar] = []byte(email)
+
+	target := data.SecretTarget{
+		Enabled:    true,
+		Name:       name,
+		Namespaces: []string{data.AppConfig.TykPodNamespace},
+	}
+
+	return ApplyBootstrapSecret(ctx, target, constants.TykBootstrapAdminCredentialsLabel, secretData)
+}
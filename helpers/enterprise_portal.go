@@ -0,0 +1,192 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/data"
+	"tyk/tyk/bootstrap/k8s"
+	"tyk/tyk/bootstrap/portal"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EnterprisePortalApiToken is the key of the API token of the Enterprise Developer Portal admin in its
+// credentials secret, next to constants.EnterprisePortalAdminEmailEnvVar and
+// constants.EnterprisePortalAdminPasswordEnvVar.
+const EnterprisePortalApiToken = "ENTERPRISE_PORTAL_API_TOKEN"
+
+// BootstrapEnterprisePortal creates the admin user of the Tyk Enterprise Developer Portal, unless the API
+// token stored by a previous run is still accepted, and connects orgs to the portal as providers.
+func BootstrapEnterprisePortal(ctx context.Context, client *portal.Client, orgs []*data.Organisation) error {
+	token, err := EnsureEnterprisePortalAdmin(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	for _, org := range orgs {
+		err = ConnectEnterprisePortalProvider(ctx, client.Admin(token), org)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// enterprisePortalCredentialsSecret returns the target of the secret holding the credentials of the
+// Enterprise Developer Portal admin, in the namespace Tyk is deployed to.
+func enterprisePortalCredentialsSecret() data.SecretTarget {
+	return data.SecretTarget{
+		Enabled:    true,
+		Name:       data.AppConfig.EnterprisePortalCredentialsSecretName,
+		Namespaces: []string{data.AppConfig.TykPodNamespace},
+	}
+}
+
+// EnsureEnterprisePortalAdmin returns the API token of the Enterprise Developer Portal admin. The token
+// stored in the credentials secret by a previous run is reused if the portal accepts it. Otherwise the admin
+// is created, with the password stored in the secret if none is configured, or a generated one. The email,
+// password and API token of the admin are stored in the secret.
+func EnsureEnterprisePortalAdmin(ctx context.Context, client *portal.Client) (string, error) {
+	clientset, err := k8s.NewClientset()
+	if err != nil {
+		return "", err
+	}
+
+	target := enterprisePortalCredentialsSecret()
+	email := data.AppConfig.EnterprisePortalAdminEmailAddress
+	password := data.AppConfig.EnterprisePortalAdminPassword
+
+	var token string
+	secret, err := clientset.CoreV1().Secrets(data.AppConfig.TykPodNamespace).Get(ctx, target.Name, v1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return "", fmt.Errorf("failed to get enterprise portal credentials secret %v, err: %v", target.Name, err)
+	case string(secret.Data[constants.EnterprisePortalAdminEmailEnvVar]) == email:
+		if password == "" {
+			password = string(secret.Data[constants.EnterprisePortalAdminPasswordEnvVar])
+		}
+		token = string(secret.Data[EnterprisePortalApiToken])
+	}
+
+	if token != "" {
+		_, err = client.Admin(token).ListProviders(ctx)
+		switch code := portal.StatusCode(err); {
+		case err == nil:
+			fmt.Printf("Reusing the API token of enterprise portal admin %v stored in secret %v\n", email, target.Name)
+			return token, nil
+		case code == http.StatusUnauthorized || code == http.StatusForbidden:
+			fmt.Printf("Enterprise portal rejected the API token stored in secret %v, creating admin %v\n",
+				target.Name, email)
+		default:
+			return "", fmt.Errorf("failed to check the enterprise portal API token, err: %v", err)
+		}
+	}
+
+	if password == "" {
+		password, err = data.GeneratePassword(email)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// The password is stored before the admin is created, so that it is not lost if the portal accepts it
+	// but its response is not received.
+	secretData := map[string][]byte{
+		constants.EnterprisePortalAdminEmailEnvVar:    []byte(email),
+		constants.EnterprisePortalAdminPasswordEnvVar: []byte(password),
+	}
+	err = ApplyBootstrapSecret(ctx, target, constants.TykBootstrapEnterprisePortalCredentialsLabel, secretData)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Printf("Creating enterprise portal admin %v\n", email)
+	token, err = client.Bootstrap(ctx, portal.BootstrapRequest{
+		Username:  email,
+		Password:  password,
+		FirstName: data.AppConfig.EnterprisePortalAdminFirstName,
+		LastName:  data.AppConfig.EnterprisePortalAdminLastName,
+	})
+	if code := portal.StatusCode(err); code >= 400 && code < 500 {
+		return "", fmt.Errorf("failed to create enterprise portal admin, if the portal is already "+
+			"bootstrapped, store the API token of its admin in secret %v under key %v, err: %v",
+			target.Name, EnterprisePortalApiToken, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create enterprise portal admin, err: %v", err)
+	}
+
+	secretData[EnterprisePortalApiToken] = []byte(token)
+	err = ApplyBootstrapSecret(ctx, target, constants.TykBootstrapEnterprisePortalCredentialsLabel, secretData)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ConnectEnterprisePortalProvider creates or updates the provider of the Enterprise Developer Portal named
+// after org, which connects the portal to the Dashboard with the access key of the org admin, and
+// synchronizes the policies and APIs of org into the portal.
+func ConnectEnterprisePortalProvider(ctx context.Context, client *portal.AdminClient, org *data.Organisation) error {
+	metaData, err := json.Marshal(portal.ProviderMetaData{
+		URL:                data.AppConfig.DashboardUrl,
+		Secret:             org.UserAuth,
+		OrgID:              org.ID,
+		InsecureSkipVerify: data.AppConfig.DashboardInsecureSkipVerify,
+	})
+	if err != nil {
+		return err
+	}
+
+	provider := portal.Provider{
+		Name:          org.Name,
+		Type:          portal.ProviderTypeTykPro,
+		Configuration: portal.ProviderConfiguration{MetaData: string(metaData)},
+	}
+
+	providers, err := client.ListProviders(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list enterprise portal providers, err: %v", err)
+	}
+
+	providerID := 0
+	for _, existing := range providers {
+		if existing.Name == provider.Name {
+			providerID = existing.ID
+			break
+		}
+	}
+
+	if providerID != 0 {
+		fmt.Printf("Updating enterprise portal provider %v\n", provider.Name)
+		err = client.UpdateProvider(ctx, providerID, provider)
+		if err != nil {
+			return fmt.Errorf("failed to update enterprise portal provider %v, err: %v", provider.Name, err)
+		}
+	} else {
+		fmt.Printf("Creating enterprise portal provider %v\n", provider.Name)
+		created, err := client.CreateProvider(ctx, provider)
+		if err != nil {
+			return fmt.Errorf("failed to create enterprise portal provider %v, err: %v", provider.Name, err)
+		}
+		providerID = created.ID
+	}
+
+	if providerID == 0 {
+		return fmt.Errorf("enterprise portal did not return the ID of provider %v", provider.Name)
+	}
+
+	err = client.SynchronizeProvider(ctx, providerID)
+	if err != nil {
+		return fmt.Errorf("failed to synchronize enterprise portal provider %v, err: %v", provider.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tyk/tyk/bootstrap/data"
+	"tyk/tyk/bootstrap/portal"
+)
+
+func TestConnectEnterprisePortalProvider(t *testing.T) {
+	tests := []struct {
+		name         string
+		providers    string
+		createStatus int
+		wantRequests string
+		wantErr      string
+	}{
+		{
+			name:      "create",
+			providers: `[{"ID":3,"Name":"other","Type":"tyk-pro"}]`,
+			wantRequests: "[GET /portal-api/providers POST /portal-api/providers " +
+				"PUT /portal-api/providers/4/synchronize]",
+		},
+		{
+			name:      "update",
+			providers: `[{"ID":3,"Name":"other","Type":"tyk-pro"},{"ID":5,"Name":"tyk","Type":"tyk-pro"}]`,
+			wantRequests: "[GET /portal-api/providers PUT /portal-api/providers/5 " +
+				"PUT /portal-api/providers/5/synchronize]",
+		},
+		{
+			name:         "create rejected",
+			providers:    `[]`,
+			createStatus: http.StatusUnprocessableEntity,
+			wantRequests: "[GET /portal-api/providers POST /portal-api/providers]",
+			wantErr: "failed to create enterprise portal provider tyk, err: portal responded to POST " +
+				"/portal-api/providers with status 422: invalid provider",
+		},
+	}
+
+	dashboardUrl := data.AppConfig.DashboardUrl
+	data.AppConfig.DashboardUrl = "http://dashboard-svc-tyk:3000"
+	defer func() { data.AppConfig.DashboardUrl = dashboardUrl }()
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+			var sent portal.Provider
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+
+				switch {
+				case r.Method == http.MethodGet:
+					fmt.Fprint(w, tc.providers)
+				case r.Method == http.MethodPost && tc.createStatus != 0:
+					w.WriteHeader(tc.createStatus)
+					fmt.Fprint(w, `{"Status":"Error","Message":"invalid provider"}`)
+				case r.Method == http.MethodPost:
+					json.NewDecoder(r.Body).Decode(&sent)
+					fmt.Fprint(w, `{"ID":4,"Name":"tyk","Type":"tyk-pro"}`)
+				case r.URL.Path == "/portal-api/providers/5":
+					json.NewDecoder(r.Body).Decode(&sent)
+				}
+			}))
+			defer srv.Close()
+
+			org := &data.Organisation{Name: "tyk", ID: "org-id", UserAuth: "user-auth"}
+			err := ConnectEnterprisePortalProvider(context.Background(),
+				portal.NewClient(srv.URL, nil).Admin("token"), org)
+
+			if fmt.Sprint(requests) != tc.wantRequests {
+				t.Errorf("expected requests %v, got %v", tc.wantRequests, requests)
+			}
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Errorf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var metaData portal.ProviderMetaData
+			if err := json.Unmarshal([]byte(sent.Configuration.MetaData), &metaData); err != nil {
+				t.Fatal(err)
+			}
+			if sent.Name != "tyk" || sent.Type != portal.ProviderTypeTykPro ||
+				metaData.URL != data.AppConfig.DashboardUrl || metaData.Secret != "user-auth" ||
+				metaData.OrgID != "org-id" {
+				t.Errorf("unexpected provider %+v with metadata %+v", sent, metaData)
+			}
+		})
+	}
+}
@@ -3,128 +3,245 @@ package helpers
 import (
 	"context"
 	"fmt"
-	v12 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strconv"
+	"strings"
+	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/dashboard"
 	"tyk/tyk/bootstrap/data"
+	"tyk/tyk/bootstrap/k8s"
 )
 
-func BootstrapTykOperatorSecret() error {
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		return err
-	}
+// EnsureOperatorUser creates the operator service user of org, whose access key is stored in the operator
+// secret instead of the admin user's, and stores its access key in org.OperatorAuth. An existing service
+// user is updated and reused. If the Dashboard does not return its access key, the one stored in the operator
+// secret by a previous run is reused, and the bootstrapping fails if there is none, as the user is never
+// recreated.
+func EnsureOperatorUser(ctx context.Context, client *dashboard.Client, org *data.Organisation) error {
+	user := org.OperatorUser
 
-	clientset, err := kubernetes.NewForConfig(config)
+	users, err := client.User(org.UserAuth).ListUsers(ctx)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to list users of organisation %v, err: %v", org, err)
 	}
 
-	secrets, err := clientset.CoreV1().Secrets(data.AppConfig.TykPodNamespace).
-		List(context.TODO(), v1.ListOptions{})
-	if err != nil {
-		return err
-	}
+	for _, existing := range users {
+		if !strings.EqualFold(existing.EmailAddress, user.EmailAddress) {
+			continue
+		}
 
-	for _, value := range secrets.Items {
-		if value.Name == data.AppConfig.OperatorSecretName {
-			err = clientset.CoreV1().Secrets(data.AppConfig.TykPodNamespace).
-				Delete(context.TODO(), value.Name, v1.DeleteOptions{})
+		auth := existing.AccessKey
+		if auth == "" {
+			auth, err = existingOperatorAuth(ctx, org)
 			if err != nil {
 				return err
 			}
-			fmt.Println("A previously created operator secret was identified and deleted")
-			break
+			if auth == "" {
+				return fmt.Errorf("operator user %v exists, but its access key cannot be read, reset its API "+
+					"key in the Dashboard and store it as %v in operator secret %v, or remove the user, then "+
+					"rerun", existing.EmailAddress, TykAuth, org.OperatorSecret.Name)
+			}
 		}
+
+		fmt.Printf("Reusing existing operator user %v\n", existing.EmailAddress)
+		err = client.User(org.UserAuth).UpdateUser(ctx, existing.ID, dashboard.UpdateUserRequest{
+			FirstName:       user.FirstName,
+			LastName:        user.LastName,
+			EmailAddress:    existing.EmailAddress,
+			Active:          true,
+			UserPermissions: user.Permissions,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update operator user, err: %v", err)
+		}
+
+		org.OperatorAuth = auth
+		return nil
 	}
 
-	err = CreateTykOperatorSecret(clientset)
+	fmt.Printf("Creating operator user %v\n", user.EmailAddress)
+	auth, err := CreateUser(ctx, client, org.ID, user)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to create operator user, err: %v", err)
 	}
 
+	org.OperatorAuth = auth
+
 	return nil
 }
 
-func CreateTykOperatorSecret(clientset *kubernetes.Clientset) error {
-	secretData := map[string][]byte{
-		TykAuth: []byte(data.AppConfig.UserAuth),
-		TykOrg:  []byte(data.AppConfig.OrgId),
-		TykMode: []byte(TykModePro),
-		TykUrl:  []byte(data.AppConfig.DashboardUrl),
+// existingOperatorAuth returns the access key of the operator user of org stored in its operator secret by a
+// previous run, or "" if there is none. A key equal to the one of the admin user is ignored, as it was not
+// stored for the operator user.
+func existingOperatorAuth(ctx context.Context, org *data.Organisation) (string, error) {
+	clientset, err := k8s.NewClientset()
+	if err != nil {
+		return "", err
 	}
 
-	objectMeta := v1.ObjectMeta{Name: data.AppConfig.OperatorSecretName}
-
-	secret := v12.Secret{
-		ObjectMeta: objectMeta,
-		Data:       secretData,
-	}
-	_, err := clientset.CoreV1().Secrets(data.AppConfig.TykPodNamespace).
-		Create(context.TODO(), &secret, v1.CreateOptions{})
+	namespaces, err := org.OperatorSecret.ResolveNamespaces(ctx)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	for _, ns := range namespaces {
+		secret, err := clientset.CoreV1().Secrets(ns).Get(ctx, org.OperatorSecret.Name, v1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get operator secret %v/%v, err: %v", ns, org.OperatorSecret.Name, err)
+		}
+
+		auth := string(secret.Data[TykAuth])
+		if string(secret.Data[TykOrg]) == org.ID && auth != "" && auth != org.UserAuth {
+			return auth, nil
+		}
+	}
+
+	return "", nil
 }
 
-func BootstrapTykPortalSecret() error {
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		return err
+// BootstrapTykOperatorSecret creates or updates the operator secret of org, along with the TLS settings of
+// the Dashboard and the extra keys of the secret.
+func BootstrapTykOperatorSecret(org *data.Organisation) error {
+	secretData := map[string][]byte{}
+	for key, value := range org.OperatorSecretExtraData {
+		secretData[key] = []byte(value)
 	}
 
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return err
+	secretData[TykAuth] = []byte(org.OperatorAuth)
+	secretData[TykOrg] = []byte(org.ID)
+	secretData[TykMode] = []byte(TykModePro)
+	secretData[TykUrl] = []byte(data.AppConfig.DashboardUrl)
+	secretData[TykTlsInsecureSkipVerify] = []byte(strconv.FormatBool(data.AppConfig.DashboardInsecureSkipVerify))
+	if data.AppConfig.DashboardCACert != "" {
+		secretData[TykCACert] = []byte(data.AppConfig.DashboardCACert)
 	}
 
-	secrets, err := clientset.CoreV1().Secrets(data.AppConfig.TykPodNamespace).
-		List(context.TODO(), v1.ListOptions{})
+	return ApplyBootstrapSecret(context.TODO(), org.OperatorSecret, constants.TykBootstrapOperatorSecretLabel,
+		secretData)
+}
+
+// OperatorContextResource is the resource of the OperatorContext custom resources of Tyk Operator.
+var OperatorContextResource = schema.GroupVersionResource{
+	Group:    "tyk.tyk.io",
+	Version:  "v1alpha1",
+	Resource: "operatorcontexts",
+}
+
+// BootstrapOperatorContext creates or updates the OperatorContext of org, which references its operator
+// secret. The secret in the namespace of the OperatorContext is preferred, if it is written there.
+func BootstrapOperatorContext(ctx context.Context, org *data.Organisation) error {
+	target := org.OperatorContext
+
+	namespaces, err := org.OperatorSecret.ResolveNamespaces(ctx)
 	if err != nil {
 		return err
 	}
+	if len(namespaces) == 0 {
+		return fmt.Errorf("operator context %v/%v cannot reference operator secret %v, which is not written "+
+			"to any namespace", target.Namespace, target.Name, org.OperatorSecret.Name)
+	}
 
-	for _, value := range secrets.Items {
-		if data.AppConfig.DeveloperPortalSecretName == value.Name {
-			err = clientset.CoreV1().Secrets(data.AppConfig.TykPodNamespace).
-				Delete(context.TODO(), value.Name, v1.DeleteOptions{})
-			if err != nil {
-				return err
-			}
-			fmt.Println("A previously created portal secret was identified and deleted")
-			break
+	secretNamespace := namespaces[0]
+	for _, ns := range namespaces {
+		if ns == target.Namespace {
+			secretNamespace = ns
 		}
 	}
 
-	if data.AppConfig.DeveloperPortalSecretName != "" {
-		err = CreateTykPortalSecret(clientset, data.AppConfig.DeveloperPortalSecretName)
-		if err != nil {
-			return err
-		}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": OperatorContextResource.GroupVersion().String(),
+		"kind":       "OperatorContext",
+		"spec": map[string]interface{}{
+			"secretRef": map[string]interface{}{
+				"name":      org.OperatorSecret.Name,
+				"namespace": secretNamespace,
+			},
+		},
+	}}
+	obj.SetName(target.Name)
+	obj.SetNamespace(target.Namespace)
+	obj.SetLabels(BootstrapLabels(constants.TykBootstrapOperatorContextLabel))
+	if annotations := helmReleaseAnnotations(); annotations != nil {
+		obj.SetAnnotations(annotations)
+	}
+
+	err = k8s.ApplyObject(ctx, OperatorContextResource, obj)
+	if err != nil {
+		return err
 	}
+
+	fmt.Printf("Applied operator context %v/%v\n", target.Namespace, target.Name)
+
 	return nil
 }
 
-func CreateTykPortalSecret(clientset *kubernetes.Clientset, secretName string) error {
+// BootstrapTykPortalSecret creates or updates the portal secret of org.
+func BootstrapTykPortalSecret(org *data.Organisation) error {
+	if org.PortalSecret.Name == "" {
+		return nil
+	}
+
 	secretData := map[string][]byte{
-		TykAuth: []byte(data.AppConfig.UserAuth),
-		TykOrg:  []byte(data.AppConfig.OrgId),
+		TykAuth: []byte(org.UserAuth),
+		TykOrg:  []byte(org.ID),
 	}
 
-	objectMeta := v1.ObjectMeta{Name: secretName}
+	return ApplyBootstrapSecret(context.TODO(), org.PortalSecret, constants.TykBootstrapPortalSecretLabel,
+		secretData)
+}
 
-	secret := v12.Secret{
-		ObjectMeta: objectMeta,
-		Data:       secretData,
+// ExistingSecretCredentials returns the access keys of org stored by previous runs, to look its first admin
+// user up with: the one stored in the credentials secret of the admin user, then the one of the portal
+// secret. The operator secret is not used, as it holds the access key of a service user that cannot list
+// users.
+func ExistingSecretCredentials(ctx context.Context, org *data.Organisation) ([]string, error) {
+	clientset, err := k8s.NewClientset()
+	if err != nil {
+		return nil, err
 	}
-	_, err := clientset.CoreV1().Secrets(data.AppConfig.TykPodNamespace).
-		Create(context.TODO(), &secret, v1.CreateOptions{})
+
+	admin := org.AdminUsers[0]
+	secret, err := clientset.CoreV1().Secrets(data.AppConfig.TykPodNamespace).
+		Get(ctx, admin.PasswordSecretName, v1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get credentials secret %v, err: %v", admin.PasswordSecretName, err)
+	}
+
+	var auths []string
+	if err == nil && string(secret.Data[TykOrg]) == org.ID &&
+		strings.EqualFold(string(secret.Data[constants.TykAdminEmailEnvVar]), admin.EmailAddress) &&
+		len(secret.Data[TykAuth]) > 0 {
+		auths = append(auths, string(secret.Data[TykAuth]))
+	}
+
+	if org.PortalSecret.Name == "" {
+		return auths, nil
+	}
+
+	namespaces, err := org.PortalSecret.ResolveNamespaces(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	for _, ns := range namespaces {
+		secret, err := clientset.CoreV1().Secrets(ns).Get(ctx, org.PortalSecret.Name, v1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if string(secret.Data[TykOrg]) == org.ID && len(secret.Data[TykAuth]) > 0 {
+			auths = append(auths, string(secret.Data[TykAuth]))
+		}
+	}
+
+	return auths, nil
 }
@@ -1,122 +1,194 @@
 package helpers
 
 import (
-	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/dashboard"
 	"tyk/tyk/bootstrap/data"
-
-	"k8s.io/apimachinery/pkg/util/json"
 )
 
 const (
-	AdminOrganisationsEndpoint     = "/admin/organisations"
-	ApiUsersActionsResetEndpoint   = "%s/api/users/%s/actions/reset"
-	ApiPortalCatalogueEndpoint     = "/api/portal/catalogue"
-	ApiPortalPagesEndpoint         = "/api/portal/pages"
-	ApiPortalConfigurationEndpoint = "/api/portal/configuration"
-	ApiPortalCnameEndpoint         = "/api/portal/cname"
-
 	TykModePro = "pro"
 	TykAuth    = "TYK_AUTH"
 	TykOrg     = "TYK_ORG"
 	TykMode    = "TYK_MODE"
 	TykUrl     = "TYK_URL"
+
+	TykTlsInsecureSkipVerify = "TYK_TLS_INSECURE_SKIP_VERIFY"
+	// TykCACert holds the CA bundle of the Dashboard certificate, meant to be mounted as a file.
+	TykCACert = "ca.crt"
 )
 
-func CheckForExistingOrganisation(client http.Client) error {
-	fmt.Println("Checking for existing organisations")
+// CheckForExistingOrganisation fails if an organisation with the name or cname of org already exists. If
+// org.AdoptExisting is set, the matching organisation is adopted instead by storing its ID in org.ID.
+func CheckForExistingOrganisation(ctx context.Context, client *dashboard.Client, org *data.Organisation) error {
+	fmt.Printf("Checking for existing organisations matching %v\n", org)
 
-	orgsApiEndpoint := data.AppConfig.DashboardUrl + AdminOrganisationsEndpoint
-	req, err := http.NewRequest("GET", orgsApiEndpoint, nil)
+	existing, err := FindExistingOrganisation(ctx, client, org)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("admin-auth", data.AppConfig.TykAdminSecret)
-	req.Header.Set("Content-Type", "application/json")
-	res, err := client.Do(req)
-	if err != nil {
-		return err
+	if existing == nil {
+		fmt.Println("No organisations have been detected, we can proceed")
+		return nil
 	}
 
-	bodyBytes, err := io.ReadAll(res.Body)
-	if err != nil {
-		fmt.Println(err)
+	if org.AdoptExisting {
+		fmt.Printf("Adopting existing organisation %q with ID %v\n", existing.OwnerName, existing.ID)
+		org.ID = existing.ID
+		return nil
 	}
 
-	orgs := OrgResponse{}
-	err = json.Unmarshal(bodyBytes, &orgs)
+	return fmt.Errorf("organisation %q with cname %q already exists, please disable bootstrapping to "+
+		"avoid losing data, delete the existing organisation or enable adoption of existing organisations",
+		existing.OwnerName, existing.Cname)
+}
+
+// FindExistingOrganisation returns the organisation whose owner name or cname matches org, or nil if there
+// is no such organisation.
+func FindExistingOrganisation(ctx context.Context, client *dashboard.Client,
+	org *data.Organisation) (*dashboard.Organisation, error) {
+	orgs, err := client.Admin(data.AppConfig.TykAdminSecret).ListOrganisations(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if len(orgs.Organisations) > 0 {
-		for _, organisation := range orgs.Organisations {
-			if organisation["owner_name"] == data.AppConfig.CurrentOrgName ||
-				organisation["cname"] == data.AppConfig.Cname {
-				return errors.New("there shouldn't be any organisations, please " +
-					"disable bootstrapping to avoid losing data or delete " +
-					"already existing organisations")
-			}
+
+	for i := range orgs {
+		if orgs[i].OwnerName == org.Name || (org.Cname != "" && orgs[i].Cname == org.Cname) {
+			return &orgs[i], nil
 		}
-	} else {
-		fmt.Println("No organisations have been detected, we can proceed")
-		return nil
 	}
-	return nil
-}
 
-type CreateOrgStruct struct {
-	OwnerName    string `json:"owner_name"`
-	CnameEnabled bool   `json:"cname_enabled"`
-	Cname        string `json:"cname"`
+	return nil, nil
 }
 
-func CreateOrganisation(client http.Client, dashBoardUrl string) (string, error) {
-	createOrgData := CreateOrgStruct{
-		OwnerName:    data.AppConfig.CurrentOrgName,
+func CreateOrganisation(ctx context.Context, client *dashboard.Client, org *data.Organisation) (string, error) {
+	createOrgData := dashboard.CreateOrganisationRequest{
+		OwnerName:    org.Name,
 		CnameEnabled: true,
-		Cname:        data.AppConfig.Cname,
+		Cname:        org.Cname,
 	}
-	reqBodyBytes, err := json.Marshal(createOrgData)
+
+	// A failed POST may still have created the organisation, so it is looked up before the POST is retried.
+	var orgId string
+	attempt := 0
+	err := data.AppConfig.RetryPolicy.Do(ctx, isRetryable, func(ctx context.Context) error {
+		attempt++
+		if attempt > 1 {
+			existing, err := FindExistingOrganisation(ctx, client, org)
+			if err != nil {
+				return err
+			}
+
+			if existing != nil {
+				fmt.Println("Organisation was created by a previous attempt")
+				orgId = existing.ID
+				return nil
+			}
+		}
+
+		var err error
+		orgId, err = client.Admin(data.AppConfig.TykAdminSecret).CreateOrganisation(ctx, createOrgData)
+		return err
+	})
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to create organisation, err: %v", err)
 	}
-	reqBody := bytes.NewReader(reqBodyBytes)
-	req, err := http.NewRequest("POST", dashBoardUrl+AdminOrganisationsEndpoint, reqBody)
+
+	return orgId, nil
+}
+
+// BootstrapOrganisation creates org along with its users and user groups, writes its operator and portal
+// secrets and OperatorContext and bootstraps its classic portal, as configured.
+func BootstrapOrganisation(ctx context.Context, client *dashboard.Client, org *data.Organisation) error {
+	fmt.Printf("Started creating dashboard org %v\n", org)
+	err := CheckForExistingOrganisation(ctx, client, org)
 	if err != nil {
-		return "", err
+		return err
 	}
-	req.Header.Set("admin-auth", data.AppConfig.TykAdminSecret)
-	req.Header.Set("Content-Type", "application/json")
-	res, err := client.Do(req)
-	if err != nil {
-		return "", err
+
+	for _, user := range append(append([]*data.User{}, org.AdminUsers...), org.Users...) {
+		err = EnsureUserPassword(ctx, user)
+		if err != nil {
+			return err
+		}
 	}
 
-	bodyBytes, err := io.ReadAll(res.Body)
+	fmt.Println("Generating dashboard credentials")
+	err = GenerateDashboardCredentials(ctx, client, org)
 	if err != nil {
-		fmt.Println(err)
+		return err
 	}
+	fmt.Println("Finished generating dashboard credentials")
 
-	createOrgResponse := DashboardGeneralResponse{}
-	err = json.Unmarshal(bodyBytes, &createOrgResponse)
-	if err != nil {
-		return "", err
+	if len(org.Users) > 0 || len(org.UserGroups) > 0 {
+		fmt.Println("Creating users and user groups")
+		err = CreateUsers(ctx, client, org)
+		if err != nil {
+			return err
+		}
 	}
 
-	return createOrgResponse.Meta, nil
-}
+	if org.OperatorSecret.Enabled {
+		fmt.Println("Started bootstrapping operator secret")
+		err = EnsureOperatorUser(ctx, client, org)
+		if err != nil {
+			return err
+		}
 
-type DashboardGeneralResponse struct {
-	Status  string `json:"Status"`
-	Message string `json:"Message"`
-	Meta    string `json:"Meta"`
+		err = BootstrapTykOperatorSecret(org)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Finished bootstrapping operator secret")
+	}
+
+	if org.OperatorContext.Enabled {
+		fmt.Println("Started bootstrapping operator context")
+		err = BootstrapOperatorContext(ctx, org)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Finished bootstrapping operator context")
+	}
+
+	if org.PortalSecret.Enabled {
+		fmt.Println("Started bootstrapping portal secret")
+		err = BootstrapTykPortalSecret(org)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Finished bootstrapping portal secret")
+	}
+
+	// The Enterprise Developer Portal is bootstrapped once all organisations are, see BootstrapEnterprisePortal.
+	if org.BootstrapPortal && data.AppConfig.PortalMode == constants.PortalModeClassic {
+		fmt.Println("Started bootstrapping portal with requests to dashboard")
+		err = BoostrapPortal(ctx, client, org)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Finished bootstrapping portal")
+	}
+
+	return nil
 }
 
-type OrgResponse struct {
-	Organisations []map[string]interface{} `json:"organisations"`
-	Pages         int                      `json:"pages"`
+// isRetryable reports whether a failed Dashboard call may succeed if retried, which is the case for
+// connection errors, timeouts and responses with a status code that is retryable according to
+// data.AppConfig.RetryPolicy.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	code := dashboard.StatusCode(err)
+	if code == 0 {
+		return true
+	}
+
+	return data.AppConfig.RetryPolicy.IsRetryableStatus(code)
 }
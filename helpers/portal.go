@@ -1,41 +1,51 @@
 package helpers
 
 import (
-	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
-	"io"
+	"net/http"
 
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/json"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
-	"net/http"
 	"time"
 	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/dashboard"
 	"tyk/tyk/bootstrap/data"
+	"tyk/tyk/bootstrap/k8s"
+	"tyk/tyk/bootstrap/readiness"
 )
 
-func BoostrapPortal(client http.Client) error {
-	err := CreatePortalDefaultSettings(client)
+// BoostrapPortal bootstraps the portal of org. The Dashboard must be restarted with RestartDashboard
+// afterwards to apply the portal cname.
+func BoostrapPortal(ctx context.Context, client *dashboard.Client, org *data.Organisation) error {
+	err := ConfigurePortal(ctx, client, org)
 	if err != nil {
 		return err
 	}
 
-	err = InitialiseCatalogue(client)
+	err = InitialiseCatalogue(ctx, client, org)
 	if err != nil {
 		return err
 	}
 
-	err = CreatePortalHomepage(client)
+	if len(org.PortalCatalogue) > 0 {
+		err = PublishCatalogueAPIs(ctx, client, org)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = CreatePortalPages(ctx, client, org)
 	if err != nil {
 		return err
 	}
 
-	err = SetPortalCname(client)
+	err = SetPortalCname(ctx, client, org)
 	if err != nil {
 		return err
 	}
@@ -43,235 +53,504 @@ func BoostrapPortal(client http.Client) error {
 	return nil
 }
 
-type InitCatalogReq struct {
-	OrgId string `json:"org_id"`
-}
-
-type CnameRequest struct {
-	Cname string `json:"cname"`
-}
-
-func SetPortalCname(client http.Client) error {
+func SetPortalCname(ctx context.Context, client *dashboard.Client, org *data.Organisation) error {
 	fmt.Println("Setting portal cname")
 
-	cnameReq := CnameRequest{Cname: data.AppConfig.Cname}
-	reqBody, err := json.Marshal(cnameReq)
+	err := client.User(org.UserAuth).SetPortalCname(ctx, org.Cname)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to set portal cname, err: %v", err)
 	}
-	reqData := bytes.NewReader(reqBody)
 
-	req, err := http.NewRequest("PUT", data.AppConfig.DashboardUrl+ApiPortalCnameEndpoint, reqData)
+	return nil
+}
+
+// InitialiseCatalogue creates the catalogue of org, unless it exists already, and stores its ID in
+// org.CatalogId.
+func InitialiseCatalogue(ctx context.Context, client *dashboard.Client, org *data.Organisation) error {
+	catalogue, err := client.User(org.UserAuth).GetCatalogue(ctx)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get catalogue, err: %v", err)
 	}
 
-	req.Header.Set("Authorization", data.AppConfig.UserAuth)
+	if id := catalogue.String("id"); id != "" {
+		fmt.Println("Reusing existing catalogue")
+		org.CatalogId = id
+		return nil
+	}
+
+	fmt.Println("Initialising Catalogue")
 
-	res, err := client.Do(req)
+	catalogId, err := client.User(org.UserAuth).CreateCatalogue(ctx, org.ID)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to initialise catalogue, err: %v", err)
 	}
 
-	if res.StatusCode != http.StatusOK {
-		return errors.New("failed to set portal cname")
-	}
+	org.CatalogId = catalogId
 
-	// restarting the dashboard to apply the new cname
-	return RestartDashboard()
+	return nil
 }
 
-func InitialiseCatalogue(client http.Client) error {
-	fmt.Println("Initialising Catalogue")
+// PublishCatalogueAPIs publishes the catalogue entries of org in its catalogue, replacing the settings of
+// the APIs published for the same policies and keeping the other APIs and any setting the entries do not
+// declare. The documentation of an entry is uploaded only if it differs from the documentation the API
+// already refers to, which is deleted once it is replaced.
+func PublishCatalogueAPIs(ctx context.Context, client *dashboard.Client, org *data.Organisation) error {
+	fmt.Println("Publishing catalogue APIs")
 
-	initCatalog := InitCatalogReq{OrgId: data.AppConfig.OrgId}
-	reqBody, err := json.Marshal(initCatalog)
+	user := client.User(org.UserAuth)
+	catalogue, err := user.GetCatalogue(ctx)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get catalogue, err: %v", err)
 	}
-	reqData := bytes.NewReader(reqBody)
-	req, err := http.NewRequest("POST", data.AppConfig.DashboardUrl+ApiPortalCatalogueEndpoint, reqData)
-	if err != nil {
-		return err
+	if catalogue.String("id") == "" {
+		catalogue["id"] = org.CatalogId
 	}
+	catalogue["org_id"] = org.ID
+
+	apis, _ := catalogue["apis"].([]interface{})
+	var replacedDocs []string
+	for _, entry := range org.PortalCatalogue {
+		api := dashboard.RawObject{
+			"name":              entry.Name,
+			"short_description": entry.ShortDescription,
+			"long_description":  entry.LongDescription,
+			"show":              entry.Visible,
+			"policy_id":         entry.PolicyID,
+			"version":           "v2",
+			"config": dashboard.RawObject{
+				"override":             entry.RequireKeyApproval || len(entry.KeyRequestFields) > 0,
+				"require_key_approval": entry.RequireKeyApproval,
+				"key_request_fields":   entry.KeyRequestFields,
+			},
+		}
 
-	req.Header.Set("Authorization", data.AppConfig.UserAuth)
+		var existing dashboard.RawObject
+		for _, a := range apis {
+			if published, ok := a.(map[string]interface{}); ok && published["policy_id"] == entry.PolicyID {
+				existing = published
+				break
+			}
+		}
 
-	res, err := client.Do(req)
-	if err != nil || res.StatusCode != http.StatusOK {
-		return err
-	}
+		if len(entry.Documentation) > 0 {
+			docID, err := publishDocumentation(ctx, user, existing.String("documentation"), entry.Documentation)
+			if err != nil {
+				return fmt.Errorf("failed to upload documentation of catalogue API %v, err: %v", entry.Name, err)
+			}
+			if previous := existing.String("documentation"); previous != "" && previous != docID {
+				replacedDocs = append(replacedDocs, previous)
+			}
+			api["documentation"] = docID
+		}
 
-	resp := DashboardGeneralResponse{}
+		if existing != nil {
+			fmt.Printf("Updating catalogue API %v\n", entry.Name)
+			existing.Merge(api)
+			continue
+		}
 
-	bodyBytes, err := io.ReadAll(res.Body)
-	if err != nil {
-		fmt.Println(err)
+		fmt.Printf("Publishing catalogue API %v\n", entry.Name)
+		api["fields"] = map[string]interface{}{}
+		apis = append(apis, api)
 	}
+	catalogue["apis"] = apis
 
-	err = json.Unmarshal(bodyBytes, &resp)
+	err = user.UpdateCatalogue(ctx, catalogue)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to update catalogue, err: %v", err)
 	}
 
-	data.AppConfig.CatalogId = resp.Message
+	// Replaced documentation is only deleted once the catalogue no longer refers to it.
+	for _, docID := range replacedDocs {
+		err = user.DeleteDocumentation(ctx, docID)
+		if err != nil && dashboard.StatusCode(err) != http.StatusNotFound {
+			fmt.Printf("[WARNING] Failed to delete replaced catalogue documentation %v, err: %v\n", docID, err)
+		}
+	}
 
 	return nil
 }
 
-func CreatePortalHomepage(client http.Client) error {
-	fmt.Println("Creating portal homepage")
+// publishDocumentation returns the ID of the documentation doc of a catalogue API. The documentation with
+// ID docID, if any, is reused if its content is doc, otherwise doc is uploaded.
+func publishDocumentation(ctx context.Context, user *dashboard.UserClient, docID string, doc []byte) (string, error) {
+	encoded := base64.StdEncoding.EncodeToString(doc)
+
+	if docID != "" {
+		existing, err := user.GetDocumentation(ctx, docID)
+		switch {
+		case dashboard.StatusCode(err) == http.StatusNotFound:
+		case err != nil:
+			return "", err
+		case existing.Documentation == encoded || existing.Documentation == string(doc):
+			return docID, nil
+		}
+	}
+
+	return user.CreateDocumentation(ctx, dashboard.CreateDocumentationRequest{
+		DocType:       "swagger",
+		Documentation: encoded,
+	})
+}
 
-	homepageContents := GetPortalHomepage()
-	reqBody, err := json.Marshal(homepageContents)
+// CreatePortalPages creates the pages of org returned by GetPortalPages, or updates the existing pages with
+// the same slugs.
+func CreatePortalPages(ctx context.Context, client *dashboard.Client, org *data.Organisation) error {
+	fmt.Println("Creating portal pages")
+
+	existing, err := client.User(org.UserAuth).ListPortalPages(ctx)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to list portal pages, err: %v", err)
 	}
-	reqData := bytes.NewReader(reqBody)
-	req, err := http.NewRequest("POST", data.AppConfig.DashboardUrl+ApiPortalPagesEndpoint, reqData)
-	req.Header.Set("Authorization", data.AppConfig.UserAuth)
-	if err != nil {
-		return err
+
+	ids := map[string]string{}
+	for _, page := range existing {
+		ids[page.Slug] = page.ID
 	}
-	res, err := client.Do(req)
-	if err != nil || res.StatusCode != http.StatusOK {
-		return err
+
+	for _, page := range GetPortalPages(org) {
+		if id, ok := ids[page.Slug]; ok {
+			fmt.Printf("Updating portal page %v\n", page.Slug)
+			err = client.User(org.UserAuth).UpdatePortalPage(ctx, id, page)
+		} else {
+			fmt.Printf("Creating portal page %v\n", page.Slug)
+			_, err = client.User(org.UserAuth).CreatePortalPage(ctx, page)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create portal page %v, err: %v", page.Slug, err)
+		}
 	}
-	resp := DashboardGeneralResponse{}
-	bodyBytes, err := io.ReadAll(res.Body)
-	if err != nil {
-		fmt.Println(err)
+
+	return nil
+}
+
+// GetPortalPages returns the homepage and the other pages configured for org, or the default homepage if
+// no page is configured.
+func GetPortalPages(org *data.Organisation) []dashboard.PortalPage {
+	if org.PortalHomepage == nil && len(org.PortalPages) == 0 {
+		return []dashboard.PortalPage{GetPortalHomepage(org)}
 	}
 
-	err = json.Unmarshal(bodyBytes, &resp)
-	if err != nil {
-		return err
+	var pages []dashboard.PortalPage
+	if org.PortalHomepage != nil {
+		pages = append(pages, GetPortalHomepage(org))
 	}
 
-	return nil
+	for _, page := range org.PortalPages {
+		pages = append(pages, dashboard.PortalPage{
+			IsHomepage:   page.Homepage,
+			TemplateName: page.TemplateName,
+			Title:        page.Title,
+			Slug:         page.Slug,
+			Fields:       page.Fields,
+		})
+	}
+
+	return pages
 }
 
-func GetPortalHomepage() PortalHomepageRequest {
-	return PortalHomepageRequest{
+// GetPortalHomepage returns the homepage configured for org in the bootstrap config file, or the default
+// homepage.
+func GetPortalHomepage(org *data.Organisation) dashboard.PortalPage {
+	if page := org.PortalHomepage; page != nil {
+		return dashboard.PortalPage{
+			IsHomepage:   true,
+			TemplateName: page.TemplateName,
+			Title:        page.Title,
+			Slug:         page.Slug,
+			Fields:       page.Fields,
+		}
+	}
+
+	return dashboard.PortalPage{
 		IsHomepage:   true,
 		TemplateName: "",
 		Title:        "Developer portal name",
 		Slug:         "/",
-		Fields: PortalFields{
-			JumboCTATitle:       "Tyk Developer Portal",
-			SubHeading:          "Sub Header",
-			JumboCTALink:        "#cta",
-			JumboCTALinkTitle:   "Your awesome APIs, hosted with Tyk!",
-			PanelOneContent:     "Panel 1 content.",
-			PanelOneLink:        "#panel1",
-			PanelOneLinkTitle:   "Panel 1 Button",
-			PanelOneTitle:       "Panel 1 Title",
-			PanelThereeContent:  "",
-			PanelThreeContent:   "Panel 3 content.",
-			PanelThreeLink:      "#panel3",
-			PanelThreeLinkTitle: "Panel 3 Button",
-			PanelThreeTitle:     "Panel 3 Title",
-			PanelTwoContent:     "Panel 2 content.",
-			PanelTwoLink:        "#panel2",
-			PanelTwoLinkTitle:   "Panel 2 Button",
-			PanelTwoTitle:       "Panel 2 Title",
+		Fields: map[string]string{
+			"JumboCTATitle":       "Tyk Developer Portal",
+			"SubHeading":          "Sub Header",
+			"JumboCTALink":        "#cta",
+			"JumboCTALinkTitle":   "Your awesome APIs, hosted with Tyk!",
+			"PanelOneContent":     "Panel 1 content.",
+			"PanelOneLink":        "#panel1",
+			"PanelOneLinkTitle":   "Panel 1 Button",
+			"PanelOneTitle":       "Panel 1 Title",
+			"PanelThereeContent":  "",
+			"PanelThreeContent":   "Panel 3 content.",
+			"PanelThreeLink":      "#panel3",
+			"PanelThreeLinkTitle": "Panel 3 Button",
+			"PanelThreeTitle":     "Panel 3 Title",
+			"PanelTwoContent":     "Panel 2 content.",
+			"PanelTwoLink":        "#panel2",
+			"PanelTwoLinkTitle":   "Panel 2 Button",
+			"PanelTwoTitle":       "Panel 2 Title",
 		},
 	}
-
 }
 
-type PortalHomepageRequest struct {
-	IsHomepage   bool         `json:"is_homepage"`
-	TemplateName string       `json:"template_name"`
-	Title        string       `json:"title"`
-	Slug         string       `json:"slug"`
-	Fields       PortalFields `json:"fields"`
-}
+// ConfigurePortal creates the portal configuration of org with the Dashboard defaults, unless it exists
+// already, and merges the configured settings into it, keeping every other setting as it is. The custom
+// CSS, JS and menus are set if configured.
+func ConfigurePortal(ctx context.Context, client *dashboard.Client, org *data.Organisation) error {
+	fmt.Println("Configuring portal")
 
-type PortalFields struct {
-	JumboCTATitle       string `json:"JumboCTATitle"`
-	SubHeading          string `json:"SubHeading"`
-	JumboCTALink        string `json:"JumboCTALink"`
-	JumboCTALinkTitle   string `json:"JumboCTALinkTitle"`
-	PanelOneContent     string `json:"PanelOneContent"`
-	PanelOneLink        string `json:"PanelOneLink"`
-	PanelOneLinkTitle   string `json:"PanelOneLinkTitle"`
-	PanelOneTitle       string `json:"PanelOneTitle"`
-	PanelThereeContent  string `json:"PanelThereeContent"`
-	PanelThreeContent   string `json:"PanelThreeContent"`
-	PanelThreeLink      string `json:"PanelThreeLink"`
-	PanelThreeLinkTitle string `json:"PanelThreeLinkTitle"`
-	PanelThreeTitle     string `json:"PanelThreeTitle"`
-	PanelTwoContent     string `json:"PanelTwoContent"`
-	PanelTwoLink        string `json:"PanelTwoLink"`
-	PanelTwoLinkTitle   string `json:"PanelTwoLinkTitle"`
-	PanelTwoTitle       string `json:"PanelTwoTitle"`
-}
+	config, err := client.User(org.UserAuth).GetPortalConfiguration(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get portal configuration, err: %v", err)
+	}
 
-func CreatePortalDefaultSettings(client http.Client) error {
-	fmt.Println("Creating bootstrap default settings")
+	if config.String("id") == "" {
+		fmt.Println("Creating portal default settings")
+		err = client.User(org.UserAuth).CreatePortalConfiguration(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to create portal default settings, err: %v", err)
+		}
+
+		if org.PortalConfiguration == nil {
+			return nil
+		}
 
-	req, err := http.NewRequest("POST", data.AppConfig.DashboardUrl+ApiPortalConfigurationEndpoint, nil)
-	req.Header.Set("Authorization", data.AppConfig.UserAuth)
+		config, err = client.User(org.UserAuth).GetPortalConfiguration(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get portal configuration, err: %v", err)
+		}
+		if config.String("id") == "" {
+			return errors.New("failed to find the portal configuration after creating it")
+		}
+	}
+
+	spec := org.PortalConfiguration
+	if spec == nil {
+		return nil
+	}
 
+	fmt.Println("Updating portal configuration")
+	config["org_id"] = org.ID
+	config.Merge(portalConfiguration(spec))
+	err = client.User(org.UserAuth).UpdatePortalConfiguration(ctx, config.String("id"), config)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to update portal configuration, err: %v", err)
 	}
 
-	res, err := client.Do(req)
-	if err != nil || res.StatusCode != http.StatusOK {
-		return err
+	if spec.CustomCSS != nil {
+		err = client.User(org.UserAuth).SetPortalCSS(ctx, dashboard.PortalCSS{OrgID: org.ID, PageCSS: *spec.CustomCSS})
+		if err != nil {
+			return fmt.Errorf("failed to set portal CSS, err: %v", err)
+		}
+	}
+
+	if spec.CustomJS != nil {
+		err = client.User(org.UserAuth).SetPortalJS(ctx, dashboard.PortalJS{OrgID: org.ID, PageJS: *spec.CustomJS})
+		if err != nil {
+			return fmt.Errorf("failed to set portal JS, err: %v", err)
+		}
+	}
+
+	if len(spec.Menus) > 0 {
+		menus := dashboard.PortalMenus{OrgID: org.ID, Menus: map[string][]dashboard.PortalMenuItem{}}
+		for name, items := range spec.Menus {
+			for _, item := range items {
+				menus.Menus[name] = append(menus.Menus[name],
+					dashboard.PortalMenuItem{Title: item.Title, URL: item.URL})
+			}
+		}
+
+		err = client.User(org.UserAuth).SetPortalMenus(ctx, menus)
+		if err != nil {
+			return fmt.Errorf("failed to set portal menus, err: %v", err)
+		}
 	}
 
 	return nil
 }
 
-func RestartDashboard() error {
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		return err
+// portalConfiguration returns the settings spec sets, as fields of the Dashboard portal configuration, so
+// that they can be merged into the current configuration without changing the settings spec leaves unset.
+func portalConfiguration(spec *data.PortalConfigurationSpec) dashboard.RawObject {
+	config := dashboard.RawObject{}
+
+	bools := []struct {
+		value *bool
+		field string
+	}{
+		{spec.SignupDisabled, "disable_signup"},
+		{spec.LoginDisabled, "disable_login"},
+		{spec.RedirectOnKeyRequest, "redirect_on_key_request"},
+	}
+	for _, b := range bools {
+		if b.value != nil {
+			config[b.field] = *b.value
+		}
 	}
 
-	clientset, err := kubernetes.NewForConfig(config)
+	if spec.AutoApproveKeyRequests != nil {
+		config["require_key_approval"] = !*spec.AutoApproveKeyRequests
+	}
+	if spec.SignupFields != nil {
+		config["signup_fields"] = spec.SignupFields
+	}
+	if spec.KeyRequestFields != nil {
+		config["key_request_fields"] = spec.KeyRequestFields
+	}
+	if spec.OAuthUsageLimit != nil {
+		config["oauth_usage_limit"] = *spec.OAuthUsageLimit
+	}
+	if spec.RedirectTo != "" {
+		config["redirect_to"] = spec.RedirectTo
+	}
+	if spec.Email != "" {
+		config["email"] = spec.Email
+	}
+
+	mailOptions := dashboard.RawObject{}
+	if spec.MailFromName != "" {
+		mailOptions["mail_from_name"] = spec.MailFromName
+	}
+	if spec.MailFromEmail != "" {
+		mailOptions["mail_from_email"] = spec.MailFromEmail
+	}
+
+	if templates := spec.EmailTemplates; templates != nil {
+		emails := dashboard.RawObject{}
+		for field, template := range map[string]*data.EmailTemplateSpec{
+			"welcome_email":        templates.Welcome,
+			"key_email":            templates.Key,
+			"reset_password_email": templates.ResetPassword,
+		} {
+			if email := emailTemplate(template); len(email) > 0 {
+				emails[field] = email
+			}
+		}
+		if len(emails) > 0 {
+			mailOptions["email_copy"] = emails
+		}
+	}
+
+	if len(mailOptions) > 0 {
+		config["mail_options"] = mailOptions
+	}
+
+	return config
+}
+
+// emailTemplate returns the content spec, which may be nil, sets, as fields of a Dashboard email template.
+func emailTemplate(spec *data.EmailTemplateSpec) dashboard.RawObject {
+	template := dashboard.RawObject{}
+	if spec == nil {
+		return template
+	}
+
+	if spec.Enabled != nil {
+		template["enabled"] = *spec.Enabled
+	}
+	if spec.HideTokenData != nil {
+		template["hide_token_data"] = *spec.HideTokenData
+	}
+	if spec.Subject != "" {
+		template["subject"] = spec.Subject
+	}
+	if spec.Body != "" {
+		template["body"] = spec.Body
+	}
+	if spec.SignOff != "" {
+		template["sign_off"] = spec.SignOff
+	}
+
+	return template
+}
+
+// DashboardDeployment returns the Tyk Dashboard Deployment, named by data.AppConfig.DashboardDeploymentName
+// or found by its constants.TykBootstrapLabel label, and remembers its name.
+func DashboardDeployment(ctx context.Context) (*appsv1.Deployment, error) {
+	clientset, err := k8s.NewClientset()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if data.AppConfig.DashboardDeploymentName == "" {
-		ls := metav1.LabelSelector{MatchLabels: map[string]string{
-			constants.TykBootstrapLabel: constants.TykBootstrapDashboardDeployLabel,
-		}}
-
-		deployments, err := clientset.
-			AppsV1().
-			Deployments(data.AppConfig.TykPodNamespace).
-			List(
-				context.TODO(),
-				metav1.ListOptions{
-					LabelSelector: labels.Set(ls.MatchLabels).String(),
-				},
-			)
+	deployments := clientset.AppsV1().Deployments(data.AppConfig.TykPodNamespace)
+
+	if data.AppConfig.DashboardDeploymentName != "" {
+		deployment, err := deployments.Get(ctx, data.AppConfig.DashboardDeploymentName, metav1.GetOptions{})
 		if err != nil {
-			return errors.New(fmt.Sprintf("failed to list Tyk Dashboard Deployment, err: %v", err))
+			return nil, fmt.Errorf("failed to get Tyk Dashboard Deployment, err: %v", err)
 		}
 
-		for _, deployment := range deployments.Items {
-			data.AppConfig.DashboardDeploymentName = deployment.ObjectMeta.Name
+		return deployment, nil
+	}
+
+	ls := metav1.LabelSelector{MatchLabels: map[string]string{
+		constants.TykBootstrapLabel: constants.TykBootstrapDashboardDeployLabel,
+	}}
+
+	list, err := deployments.List(ctx, metav1.ListOptions{LabelSelector: labels.Set(ls.MatchLabels).String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Tyk Dashboard Deployment, err: %v", err)
+	}
+
+	if len(list.Items) == 0 {
+		return nil, errors.New("failed to find Tyk Dashboard Deployment")
+	}
+
+	deployment := &list.Items[len(list.Items)-1]
+	data.AppConfig.DashboardDeploymentName = deployment.Name
+
+	return deployment, nil
+}
+
+// RestartDashboard restarts the Tyk Dashboard Deployment to apply the portal cnames, and waits for its
+// rollout to complete, for at most data.AppConfig.DashboardRolloutTimeout. It fails early if a new Pod
+// cannot start, e.g. because it is in CrashLoopBackOff.
+func RestartDashboard(ctx context.Context) error {
+	clientset, err := k8s.NewClientset()
+	if err != nil {
+		return err
+	}
+
+	if data.AppConfig.DashboardDeploymentName == "" {
+		if _, err := DashboardDeployment(ctx); err != nil {
+			return err
 		}
 	}
 
+	return restartDashboard(ctx, clientset, data.AppConfig.DashboardDeploymentName)
+}
+
+// restartDashboard restarts the Tyk Dashboard Deployment name with clientset and waits for its rollout, see
+// RestartDashboard.
+func restartDashboard(ctx context.Context, clientset kubernetes.Interface, name string) error {
 	timeStamp := fmt.Sprintf(`{"spec": {"template": {"metadata": {"annotations": {"kubectl.kubernetes.io/restartedAt": "%s"}}}}}`,
 		time.Now().Format("20060102150405"))
 
-	_, err = clientset.
+	_, err := clientset.
 		AppsV1().
 		Deployments(data.AppConfig.TykPodNamespace).
 		Patch(
-			context.TODO(),
-			data.AppConfig.DashboardDeploymentName,
+			ctx,
+			name,
 			types.StrategicMergePatchType,
 			[]byte(timeStamp),
 			metav1.PatchOptions{},
 		)
-	return err
+	if err != nil {
+		return fmt.Errorf("failed to restart dashboard deployment %v, err: %v", name, err)
+	}
+
+	fmt.Printf("Restarted dashboard deployment %v, waiting for its rollout\n", name)
+
+	// The Deployment is listed after the patch, so its rollout is not complete until the new generation
+	// is observed.
+	waitCtx, cancel := context.WithTimeout(ctx, data.AppConfig.DashboardRolloutTimeout)
+	defer cancel()
+
+	err = readiness.WaitForTargets(waitCtx, clientset, data.AppConfig.TykPodNamespace,
+		[]readiness.Target{{Kind: readiness.KindDeployment, Name: name}})
+	if err != nil {
+		return fmt.Errorf("dashboard deployment %v did not complete its rollout within %v (%v), err: %v",
+			name, data.AppConfig.DashboardRolloutTimeout, constants.DashboardRolloutTimeoutEnvVar, err)
+	}
+
+	fmt.Printf("Dashboard deployment %v rolled out\n", name)
+
+	return nil
 }
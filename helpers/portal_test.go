@@ -0,0 +1,241 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"tyk/tyk/bootstrap/dashboard"
+	"tyk/tyk/bootstrap/data"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const (
+	testNamespace      = "tyk"
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+)
+
+// dashboardReplicaSet returns the ReplicaSet of the given revision of deployment, whose Pods are labelled
+// with hash, along with a Pod of it whose container is ready, or waiting for reason after restarts.
+func dashboardReplicaSet(deployment *appsv1.Deployment, revision, hash, reason string,
+	restarts int32) (*appsv1.ReplicaSet, *v1.Pod) {
+	controller := true
+	podLabels := map[string]string{"app": "dashboard", appsv1.DefaultDeploymentUniqueLabelKey: hash}
+
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:        "dashboard-" + hash,
+		Namespace:   testNamespace,
+		Labels:      podLabels,
+		Annotations: map[string]string{revisionAnnotation: revision},
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       deployment.Name,
+			UID:        deployment.UID,
+			Controller: &controller,
+		}},
+	}}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboard-" + hash, Namespace: testNamespace, Labels: podLabels},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "dashboard"}}},
+		Status: v1.PodStatus{
+			Phase:             v1.PodRunning,
+			Conditions:        []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+			ContainerStatuses: []v1.ContainerStatus{{Name: "dashboard", Ready: true}},
+		},
+	}
+	if reason != "" {
+		pod.Status.Conditions = nil
+		pod.Status.ContainerStatuses[0] = v1.ContainerStatus{
+			Name:         "dashboard",
+			RestartCount: restarts,
+			State:        v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: reason}},
+		}
+	}
+
+	return rs, pod
+}
+
+func TestRestartDashboardWaitsForNewRevision(t *testing.T) {
+	tests := []struct {
+		name     string
+		reason   string
+		restarts int32
+		wantErr  string
+	}{
+		{name: "new revision rolled out"},
+		{
+			name:     "new revision crashing",
+			reason:   "CrashLoopBackOff",
+			restarts: 3,
+			wantErr:  "container dashboard of pod/dashboard-new is in CrashLoopBackOff after 3 restarts",
+		},
+	}
+
+	namespace, timeout := data.AppConfig.TykPodNamespace, data.AppConfig.DashboardRolloutTimeout
+	data.AppConfig.TykPodNamespace, data.AppConfig.DashboardRolloutTimeout = testNamespace, 10*time.Second
+	defer func() {
+		data.AppConfig.TykPodNamespace, data.AppConfig.DashboardRolloutTimeout = namespace, timeout
+	}()
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// The Dashboard is rolled out at revision 1, whose Pod is ready.
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "dashboard",
+					Namespace:   testNamespace,
+					UID:         "dashboard-uid",
+					Generation:  1,
+					Annotations: map[string]string{revisionAnnotation: "1"},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "dashboard"}},
+				},
+				Status: appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1,
+					AvailableReplicas: 1},
+			}
+			oldRS, oldPod := dashboardReplicaSet(deployment, "1", "old", "", 0)
+			clientset := fake.NewSimpleClientset(deployment, oldRS, oldPod)
+			deployments := clientset.AppsV1().Deployments(testNamespace)
+
+			// Like the API server, the restart bumps the generation of the Deployment.
+			gvr := appsv1.SchemeGroupVersion.WithResource("deployments")
+			clientset.PrependReactor("patch", "deployments", func(k8stesting.Action) (bool, runtime.Object, error) {
+				obj, err := clientset.Tracker().Get(gvr, testNamespace, deployment.Name)
+				if err != nil {
+					return true, nil, err
+				}
+
+				patched := obj.(*appsv1.Deployment).DeepCopy()
+				patched.Generation++
+				return true, patched, clientset.Tracker().Update(gvr, patched, testNamespace)
+			})
+
+			// The rollout starts once the waiter watches the Pods, Deployments and ReplicaSets, so that no
+			// event is missed by its informers, and has evaluated the restarted Deployment, whose status is
+			// still the one of the ready revision 1.
+			var watched sync.Map
+			watching := make(chan struct{})
+			var once sync.Once
+			clientset.PrependWatchReactor("*", func(action k8stesting.Action) (bool, watch.Interface, error) {
+				watched.Store(action.GetResource().Resource, true)
+				_, pods := watched.Load("pods")
+				_, deploymentsWatched := watched.Load("deployments")
+				_, replicaSets := watched.Load("replicasets")
+				if pods && deploymentsWatched && replicaSets {
+					once.Do(func() { close(watching) })
+				}
+				return false, nil, nil
+			})
+
+			var rolledOut int32
+			go func() {
+				<-watching
+				time.Sleep(500 * time.Millisecond)
+				ctx := context.Background()
+
+				// The controller creates the ReplicaSet of revision 2, while the old Pod is still running.
+				newRS, newPod := dashboardReplicaSet(deployment, "2", "new", tc.reason, tc.restarts)
+				clientset.AppsV1().ReplicaSets(testNamespace).Create(ctx, newRS, metav1.CreateOptions{})
+				clientset.CoreV1().Pods(testNamespace).Create(ctx, newPod, metav1.CreateOptions{})
+
+				d, _ := deployments.Get(ctx, deployment.Name, metav1.GetOptions{})
+				d.Annotations[revisionAnnotation] = "2"
+				d.Status = appsv1.DeploymentStatus{ObservedGeneration: d.Generation, Replicas: 2,
+					UpdatedReplicas: 1, AvailableReplicas: 1}
+				d, _ = deployments.Update(ctx, d, metav1.UpdateOptions{})
+				if tc.reason != "" {
+					return
+				}
+
+				atomic.StoreInt32(&rolledOut, 1)
+				clientset.CoreV1().Pods(testNamespace).Delete(ctx, oldPod.Name, metav1.DeleteOptions{})
+				d.Status.Replicas = 1
+				deployments.Update(ctx, d, metav1.UpdateOptions{})
+			}()
+
+			err := restartDashboard(context.Background(), clientset, deployment.Name)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if atomic.LoadInt32(&rolledOut) == 0 {
+				t.Error("expected the wait to last until revision 2 is rolled out")
+			}
+		})
+	}
+}
+
+func TestInitialiseCatalogue(t *testing.T) {
+	tests := []struct {
+		name         string
+		catalogue    string
+		wantRequests string
+		wantID       string
+	}{
+		{
+			name:         "existing",
+			catalogue:    `{"id":"existing-id","org_id":"org-id","apis":[]}`,
+			wantRequests: "[GET /api/portal/catalogue]",
+			wantID:       "existing-id",
+		},
+		{
+			name:         "missing",
+			wantRequests: "[GET /api/portal/catalogue POST /api/portal/catalogue]",
+			wantID:       "created-id",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+
+				switch {
+				case r.Method == http.MethodGet && tc.catalogue == "":
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprint(w, `{"Status":"Error","Message":"Catalogue not found"}`)
+				case r.Method == http.MethodGet:
+					fmt.Fprint(w, tc.catalogue)
+				default:
+					fmt.Fprint(w, `{"Status":"OK","Message":"created-id"}`)
+				}
+			}))
+			defer srv.Close()
+
+			org := &data.Organisation{ID: "org-id", UserAuth: "user-auth"}
+			err := InitialiseCatalogue(context.Background(), dashboard.NewClient(srv.URL, nil), org)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if fmt.Sprint(requests) != tc.wantRequests {
+				t.Errorf("expected requests %v, got %v", tc.wantRequests, requests)
+			}
+			if org.CatalogId != tc.wantID {
+				t.Errorf("expected catalogue ID %q, got %q", tc.wantID, org.CatalogId)
+			}
+		})
+	}
+}
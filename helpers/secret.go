@@ -0,0 +1,242 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/data"
+	"tyk/tyk/bootstrap/k8s"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v12 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BootstrapLabels returns the labels of the Secrets and custom resources generated by the bootstrapping.
+// component is the value of the constants.TykBootstrapLabel label, which tells the generated objects apart.
+func BootstrapLabels(component string) map[string]string {
+	labels := map[string]string{
+		constants.TykBootstrapLabel: component,
+		constants.AppNameLabel:      constants.TykBootstrapAppName,
+		constants.AppComponentLabel: component,
+		constants.AppPartOfLabel:    "tyk",
+		constants.AppManagedByLabel: constants.TykBootstrapAppName,
+	}
+
+	if data.AppConfig.HelmReleaseName != "" {
+		labels[constants.AppInstanceLabel] = data.AppConfig.HelmReleaseName
+	}
+
+	return labels
+}
+
+// helmReleaseAnnotations returns the annotations of the generated objects naming the Helm release, if known.
+func helmReleaseAnnotations() map[string]string {
+	if data.AppConfig.HelmReleaseName == "" {
+		return nil
+	}
+
+	return map[string]string{
+		constants.HelmReleaseNameAnnotation:      data.AppConfig.HelmReleaseName,
+		constants.HelmReleaseNamespaceAnnotation: data.AppConfig.HelmReleaseNamespace,
+	}
+}
+
+// ApplyBootstrapSecret creates or updates the Secret of target with the given data in each of its
+// namespaces, labelled by BootstrapLabels. The Secret is annotated with the Helm release, if known,
+// and owned by the Tyk Dashboard Deployment if owner references are enabled and the Deployment is in the
+// same namespace.
+func ApplyBootstrapSecret(ctx context.Context, target data.SecretTarget, component string,
+	secretData map[string][]byte) error {
+	namespaces, err := target.ResolveNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, ns := range namespaces {
+		secret := &v12.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        target.Name,
+				Namespace:   ns,
+				Labels:      BootstrapLabels(component),
+				Annotations: helmReleaseAnnotations(),
+			},
+			Type: v12.SecretTypeOpaque,
+			Data: secretData,
+		}
+
+		if data.AppConfig.SecretOwnerReferences {
+			if ns != data.AppConfig.TykPodNamespace {
+				fmt.Printf("Not setting the owner of secret %v/%v, as owners must be in the same namespace\n",
+					ns, target.Name)
+			} else {
+				deployment, err := DashboardDeployment(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to find the owner of secret %v/%v, err: %v", ns, target.Name, err)
+				}
+
+				secret.OwnerReferences = []v1.OwnerReference{{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       deployment.Name,
+					UID:        deployment.UID,
+				}}
+			}
+		}
+
+		err = k8s.ApplySecret(ctx, secret)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Applied secret %v/%v\n", ns, target.Name)
+	}
+
+	return nil
+}
+
+// CheckPermissions checks up front that the enabled operator and portal secrets and OperatorContexts of all
+// organisations, and the credentials secret of the Enterprise Developer Portal, can be written to their
+// namespaces, and that the Dashboard can be restarted if needed, reporting all missing permissions at once.
+func CheckPermissions(ctx context.Context) error {
+	var targets, readTargets []data.SecretTarget
+	var actions []authorizationv1.ResourceAttributes
+	for _, org := range data.AppConfig.Organisations {
+		for _, target := range []data.SecretTarget{org.OperatorSecret, org.PortalSecret} {
+			if target.Enabled {
+				targets = append(targets, target)
+			}
+		}
+
+		// The access key of an existing operator user is read from the operator secret if the Dashboard does
+		// not return it.
+		if org.OperatorSecret.Enabled {
+			readTargets = append(readTargets, org.OperatorSecret)
+		}
+
+		// Generated passwords, and the access key of the first admin user, are read from and stored in
+		// credentials secrets.
+		for i, user := range append(append([]*data.User{}, org.AdminUsers...), org.Users...) {
+			if (user.Password == "" && user.GeneratePassword) || i == 0 {
+				targets = append(targets, data.SecretTarget{
+					Enabled:    true,
+					Name:       user.PasswordSecretName,
+					Namespaces: []string{data.AppConfig.TykPodNamespace},
+				})
+				actions = append(actions, authorizationv1.ResourceAttributes{
+					Namespace: data.AppConfig.TykPodNamespace,
+					Verb:      "get",
+					Resource:  "secrets",
+					Name:      user.PasswordSecretName,
+				})
+			}
+		}
+
+		if c := org.OperatorContext; c.Enabled {
+			for _, verb := range []string{"create", "patch"} {
+				action := authorizationv1.ResourceAttributes{
+					Namespace: c.Namespace,
+					Verb:      verb,
+					Group:     OperatorContextResource.Group,
+					Resource:  OperatorContextResource.Resource,
+				}
+				if verb == "patch" {
+					action.Name = c.Name
+				}
+				actions = append(actions, action)
+			}
+		}
+	}
+
+	if data.AppConfig.PortalMode == constants.PortalModeEnterprise {
+		for _, org := range data.AppConfig.Organisations {
+			if org.BootstrapPortal {
+				target := enterprisePortalCredentialsSecret()
+				targets = append(targets, target)
+				actions = append(actions, authorizationv1.ResourceAttributes{
+					Namespace: data.AppConfig.TykPodNamespace,
+					Verb:      "get",
+					Resource:  "secrets",
+					Name:      target.Name,
+				})
+				break
+			}
+		}
+	}
+
+	// The namespaces must be listed to resolve namespace selectors.
+	for _, target := range targets {
+		if target.NamespaceSelector != "" {
+			err := k8s.CheckAccess(ctx, []authorizationv1.ResourceAttributes{{Verb: "list", Resource: "namespaces"}})
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	for _, target := range targets {
+		namespaces, err := target.ResolveNamespaces(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, ns := range namespaces {
+			// Server-side apply creates missing Secrets and patches existing ones. Create requests carry no
+			// name, so RBAC rules restricted to resource names do not apply to them.
+			actions = append(actions,
+				authorizationv1.ResourceAttributes{Namespace: ns, Verb: "create", Resource: "secrets"},
+				authorizationv1.ResourceAttributes{Namespace: ns, Verb: "patch", Resource: "secrets", Name: target.Name},
+			)
+		}
+	}
+
+	for _, target := range readTargets {
+		namespaces, err := target.ResolveNamespaces(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, ns := range namespaces {
+			actions = append(actions,
+				authorizationv1.ResourceAttributes{Namespace: ns, Verb: "get", Resource: "secrets", Name: target.Name})
+		}
+	}
+
+	// The Dashboard is restarted to apply the portal cnames, and its rollout is watched.
+	restartDashboard := false
+	if data.AppConfig.PortalMode == constants.PortalModeClassic {
+		for _, org := range data.AppConfig.Organisations {
+			restartDashboard = restartDashboard || org.BootstrapPortal
+		}
+	}
+	if restartDashboard {
+		ns := data.AppConfig.TykPodNamespace
+		actions = append(actions,
+			authorizationv1.ResourceAttributes{Namespace: ns, Verb: "list", Group: "apps", Resource: "deployments"},
+			authorizationv1.ResourceAttributes{Namespace: ns, Verb: "watch", Group: "apps", Resource: "deployments"},
+			authorizationv1.ResourceAttributes{Namespace: ns, Verb: "list", Group: "apps", Resource: "replicasets"},
+			authorizationv1.ResourceAttributes{Namespace: ns, Verb: "watch", Group: "apps", Resource: "replicasets"},
+			authorizationv1.ResourceAttributes{Namespace: ns, Verb: "list", Resource: "pods"},
+			authorizationv1.ResourceAttributes{Namespace: ns, Verb: "watch", Resource: "pods"},
+			authorizationv1.ResourceAttributes{Namespace: ns, Verb: "patch", Group: "apps", Resource: "deployments",
+				Name: data.AppConfig.DashboardDeploymentName},
+		)
+	}
+
+	if data.AppConfig.SecretOwnerReferences {
+		verb := "list"
+		if data.AppConfig.DashboardDeploymentName != "" {
+			verb = "get"
+		}
+		actions = append(actions, authorizationv1.ResourceAttributes{
+			Namespace: data.AppConfig.TykPodNamespace,
+			Verb:      verb,
+			Group:     "apps",
+			Resource:  "deployments",
+			Name:      data.AppConfig.DashboardDeploymentName,
+		})
+	}
+
+	return k8s.CheckAccess(ctx, actions)
+}
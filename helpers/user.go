@@ -1,111 +1,136 @@
 package helpers
 
 import (
-	"bytes"
-	"errors"
+	"context"
 	"fmt"
-	"io"
 	"net/http"
-	"time"
+	"strings"
+	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/dashboard"
 	"tyk/tyk/bootstrap/data"
-
-	"k8s.io/apimachinery/pkg/util/json"
 )
 
-func CreateUser(client http.Client, dashboardUrl string, orgId string) (string, error) {
-	userData, err := GetUserData(client, dashboardUrl, orgId)
-
+func CreateUser(ctx context.Context, client *dashboard.Client, orgId string, user *data.User) (string, error) {
+	userData, err := GetUserData(ctx, client, orgId, user)
 	if err != nil {
 		return "", err
 	}
 
-	err = SetUserPassword(client, userData.UserId, userData.AuthCode, dashboardUrl)
+	// Service users do not log in to the Dashboard, so they have no password.
+	if user.Password == "" {
+		return userData.AuthCode, nil
+	}
+
+	err = SetUserPassword(ctx, client, userData.UserId, userData.AuthCode, user)
 	if err != nil {
 		return "", err
 	}
-	return userData.AuthCode, nil
-}
 
-type ResetPasswordStruct struct {
-	NewPassword     string            `json:"new_password"`
-	UserPermissions map[string]string `json:"user_permissions"`
+	return userData.AuthCode, nil
 }
 
-func SetUserPassword(client http.Client, userId string, authCode string, dashboardUrl string) error {
-	newPasswordData := ResetPasswordStruct{
-		NewPassword:     data.AppConfig.TykAdminPassword,
-		UserPermissions: map[string]string{"IsAdmin": "admin"},
-	}
-	reqBody, err := json.Marshal(newPasswordData)
-	if err != nil {
-		return err
+// SetUserPassword sets the password and the permissions of user, whose ID is userId, authenticated with
+// authCode.
+func SetUserPassword(ctx context.Context, client *dashboard.Client, userId, authCode string, user *data.User) error {
+	newPasswordData := dashboard.ResetPasswordRequest{
+		NewPassword:     user.Password,
+		UserPermissions: user.Permissions,
 	}
 
-	req, _ := http.NewRequest(
-		"POST",
-		fmt.Sprintf(ApiUsersActionsResetEndpoint, dashboardUrl, userId),
-		bytes.NewReader(reqBody))
-	req.Header.Set("authorization", authCode)
-	req.Header.Set("Content-Type", "application/json")
-	res, err := client.Do(req)
+	// Resetting the password is safe to repeat, so it is retried even though it is a POST.
+	err := data.AppConfig.RetryPolicy.Do(ctx, isRetryable, func(ctx context.Context) error {
+		return client.User(authCode).ResetPassword(ctx, userId, newPasswordData)
+	})
 	if err != nil {
-		return err
-	}
-	if res.StatusCode != 200 {
-		return errors.New("resetting password did not work")
+		return fmt.Errorf("failed to reset user password, err: %v", err)
 	}
+
 	return nil
 }
 
-func GenerateDashboardCredentials(client http.Client) error {
-	orgId, err := CreateOrganisation(client, data.AppConfig.DashboardUrl)
+// GenerateDashboardCredentials creates org and its admin users, and stores the ID of org and the access key
+// of its first admin user in org and in the credentials secret of the user. An organisation adopted by
+// CheckForExistingOrganisation is reused.
+func GenerateDashboardCredentials(ctx context.Context, client *dashboard.Client, org *data.Organisation) error {
+	if org.ID == "" {
+		orgId, err := CreateOrganisation(ctx, client, org)
+		if err != nil {
+			return err
+		}
+
+		org.ID = orgId
+	}
+
+	var userAuth string
+	var err error
+	if org.AdoptExisting {
+		userAuth, err = AdoptAdminUser(ctx, client, org)
+	} else {
+		userAuth, err = CreateUser(ctx, client, org.ID, org.AdminUsers[0])
+	}
 	if err != nil {
 		return err
 	}
 
-	data.AppConfig.OrgId = orgId
+	org.UserAuth = userAuth
 
-	userAuth, err := CreateUser(client, data.AppConfig.DashboardUrl, orgId)
+	// The access key is stored so that the admin user can be looked up if the organisation is adopted by
+	// a later run, whether or not the portal secret is enabled.
+	admin := org.AdminUsers[0]
+	err = applyCredentialsSecret(ctx, admin.PasswordSecretName, admin.EmailAddress, map[string][]byte{
+		TykAuth: []byte(org.UserAuth),
+		TykOrg:  []byte(org.ID),
+	})
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to store the access key of %v in secret %v, err: %v",
+			admin.EmailAddress, admin.PasswordSecretName, err)
 	}
 
-	data.AppConfig.UserAuth = userAuth
+	for _, user := range org.AdminUsers[1:] {
+		err = EnsureUser(ctx, client, org, user)
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-type CreateUserRequest struct {
-	OrganisationId  string            `json:"org_id"`
-	FirstName       string            `json:"first_name"`
-	LastName        string            `json:"last_name"`
-	EmailAddress    string            `json:"email_address"`
-	Active          bool              `json:"active"`
-	UserPermissions map[string]string `json:"user_permissions"`
-}
+// EnsureUser creates a user of org, other than its first admin user. If org is adopted, an existing user
+// with the same email is updated and its password is reset instead.
+func EnsureUser(ctx context.Context, client *dashboard.Client, org *data.Organisation, user *data.User) error {
+	if org.AdoptExisting {
+		users, err := client.User(org.UserAuth).ListUsers(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list users of organisation %v, err: %v", org, err)
+		}
+
+		for _, existing := range users {
+			if !strings.EqualFold(existing.EmailAddress, user.EmailAddress) {
+				continue
+			}
+
+			fmt.Printf("Updating existing user %v\n", existing.EmailAddress)
+			err = client.User(org.UserAuth).UpdateUser(ctx, existing.ID, dashboard.UpdateUserRequest{
+				FirstName:       user.FirstName,
+				LastName:        user.LastName,
+				EmailAddress:    existing.EmailAddress,
+				Active:          true,
+				UserPermissions: user.Permissions,
+				GroupID:         user.GroupID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update user %v, err: %v", user.EmailAddress, err)
+			}
+
+			return SetUserPassword(ctx, client, existing.ID, org.UserAuth, user)
+		}
+	}
+
+	fmt.Printf("Creating user %v\n", user.EmailAddress)
+	_, err := CreateUser(ctx, client, org.ID, user)
 
-type CreateUserResponse struct {
-	Status  string `json:"Status"`
-	Message string `json:"Message"`
-	Meta    struct {
-		APIModel struct {
-		} `json:"api_model"`
-		FirstName       string `json:"first_name"`
-		LastName        string `json:"last_name"`
-		EmailAddress    string `json:"email_address"`
-		OrgID           string `json:"org_id"`
-		Active          bool   `json:"active"`
-		ID              string `json:"id"`
-		AccessKey       string `json:"access_key"`
-		UserPermissions struct {
-			IsAdmin string `json:"IsAdmin"`
-		} `json:"user_permissions"`
-		GroupID         string        `json:"group_id"`
-		PasswordMaxDays int           `json:"password_max_days"`
-		PasswordUpdated time.Time     `json:"password_updated"`
-		PWHistory       []interface{} `json:"PWHistory"`
-		CreatedAt       time.Time     `json:"created_at"`
-	} `json:"Meta"`
+	return err
 }
 
 type NeededUserData struct {
@@ -113,42 +138,85 @@ type NeededUserData struct {
 	UserId   string
 }
 
-func GetUserData(client http.Client, dashboardUrl string, orgId string) (NeededUserData, error) {
-	reqBody := CreateUserRequest{
-		OrganisationId:  orgId,
-		FirstName:       data.AppConfig.TykAdminFirstName,
-		LastName:        data.AppConfig.TykAdminLastName,
-		EmailAddress:    data.AppConfig.TykAdminEmailAddress,
+func GetUserData(ctx context.Context, client *dashboard.Client, orgId string,
+	user *data.User) (NeededUserData, error) {
+	reqBody := dashboard.CreateUserRequest{
+		OrgID:           orgId,
+		FirstName:       user.FirstName,
+		LastName:        user.LastName,
+		EmailAddress:    user.EmailAddress,
 		Active:          true,
-		UserPermissions: map[string]string{"IsAdmin": "admin"},
+		UserPermissions: user.Permissions,
+		GroupID:         user.GroupID,
 	}
-	reqBytes, err := json.Marshal(reqBody)
-	if err != nil {
-		return NeededUserData{}, err
-	}
-	reqData := bytes.NewReader(reqBytes)
-	req, err := http.NewRequest("POST", dashboardUrl+"/admin/users", reqData)
+
+	// Users cannot be looked up with the admin secret, so a retried POST fails if a failed attempt already
+	// created the user.
+	var created dashboard.User
+	attempt := 0
+	err := data.AppConfig.RetryPolicy.Do(ctx, isRetryable, func(ctx context.Context) error {
+		attempt++
+
+		var err error
+		created, err = client.Admin(data.AppConfig.TykAdminSecret).CreateUser(ctx, reqBody)
+		if err != nil && attempt > 1 && dashboard.StatusCode(err) == http.StatusBadRequest {
+			return fmt.Errorf("%w, the user may have been created by a previous attempt, set %v to true "+
+				"and rerun to adopt it", err, constants.AdoptExistingOrgEnvVar)
+		}
+
+		return err
+	})
 	if err != nil {
-		return NeededUserData{}, err
+		return NeededUserData{}, fmt.Errorf("failed to create user, err: %w", err)
 	}
 
-	req.Header.Set("admin-auth", data.AppConfig.TykAdminSecret)
-	req.Header.Set("Content-Type", "application/json")
-	res, err := client.Do(req)
+	return NeededUserData{UserId: created.ID, AuthCode: created.AccessKey}, nil
+}
+
+// AdoptAdminUser returns the access key of the first admin user of the adopted organisation org. The user
+// is looked up with the credentials stored in previously generated secrets. An existing user is reused and
+// a missing user is created. An existing user whose access key cannot be read is left untouched, and
+// adoption fails, as recreating it would lose its sessions and the references to it.
+func AdoptAdminUser(ctx context.Context, client *dashboard.Client, org *data.Organisation) (string, error) {
+	admin := org.AdminUsers[0]
+
+	auths, err := ExistingSecretCredentials(ctx, org)
 	if err != nil {
-		return NeededUserData{}, err
+		return "", err
 	}
 
-	bodyBytes, err := io.ReadAll(res.Body)
-	if err != nil {
-		fmt.Println(err)
+	for _, auth := range auths {
+		users, err := client.User(auth).ListUsers(ctx)
+		if err != nil {
+			fmt.Printf("[WARNING] Failed to list users with credentials of an existing secret, err: %v\n", err)
+			continue
+		}
+
+		for _, user := range users {
+			if !strings.EqualFold(user.EmailAddress, admin.EmailAddress) {
+				continue
+			}
+
+			if user.AccessKey != "" {
+				fmt.Printf("Reusing existing admin user %v\n", user.EmailAddress)
+				return user.AccessKey, SetUserPassword(ctx, client, user.ID, user.AccessKey, admin)
+			}
+
+			return "", fmt.Errorf("admin user %v exists in the adopted organisation, but its access key cannot "+
+				"be read, reset its API key in the Dashboard and store it as %v in secret %v, or remove the "+
+				"user, then rerun", user.EmailAddress, TykAuth, admin.PasswordSecretName)
+		}
+
+		fmt.Println("Admin user does not exist in the adopted organisation, creating it")
+		return CreateUser(ctx, client, org.ID, admin)
 	}
 
-	getUserResponse := CreateUserResponse{}
-	err = json.Unmarshal(bodyBytes, &getUserResponse)
-	if err != nil {
-		return NeededUserData{}, err
+	userAuth, err := CreateUser(ctx, client, org.ID, admin)
+	if dashboard.StatusCode(err) == http.StatusBadRequest {
+		return "", fmt.Errorf("admin user %v cannot be created in the adopted organisation, if the user "+
+			"already exists, store its access key as %v in secret %v and rerun, err: %v",
+			admin.EmailAddress, TykAuth, admin.PasswordSecretName, err)
 	}
 
-	return NeededUserData{UserId: getUserResponse.Meta.ID, AuthCode: getUserResponse.Message}, nil
+	return userAuth, err
 }
@@ -0,0 +1,75 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"tyk/tyk/bootstrap/dashboard"
+	"tyk/tyk/bootstrap/data"
+)
+
+// EnsureUserGroups creates the user groups of org, or updates existing groups with the same name, and
+// stores their IDs in the groups and in the users belonging to them.
+func EnsureUserGroups(ctx context.Context, client *dashboard.Client, org *data.Organisation) error {
+	if len(org.UserGroups) == 0 {
+		return nil
+	}
+
+	existing, err := client.User(org.UserAuth).ListUserGroups(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list user groups of organisation %v, err: %v", org, err)
+	}
+
+	ids := map[string]string{}
+	for _, group := range existing {
+		ids[group.Name] = group.ID
+	}
+
+	for _, group := range org.UserGroups {
+		req := dashboard.UserGroup{
+			OrgID:           org.ID,
+			Name:            group.Name,
+			Description:     group.Description,
+			Active:          true,
+			UserPermissions: group.Permissions,
+		}
+
+		if id, ok := ids[group.Name]; ok {
+			fmt.Printf("Updating user group %q\n", group.Name)
+			err = client.User(org.UserAuth).UpdateUserGroup(ctx, id, req)
+			group.ID = id
+		} else {
+			fmt.Printf("Creating user group %q\n", group.Name)
+			group.ID, err = client.User(org.UserAuth).CreateUserGroup(ctx, req)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create or update user group %q, err: %v", group.Name, err)
+		}
+
+		ids[group.Name] = group.ID
+	}
+
+	for _, user := range org.Users {
+		if user.Group != "" {
+			user.GroupID = ids[user.Group]
+		}
+	}
+
+	return nil
+}
+
+// CreateUsers creates the user groups and the users of org, other than its admin users.
+func CreateUsers(ctx context.Context, client *dashboard.Client, org *data.Organisation) error {
+	err := EnsureUserGroups(ctx, client, org)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range org.Users {
+		err = EnsureUser(ctx, client, org, user)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
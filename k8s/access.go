@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CheckAccess reviews whether the service account may perform the given actions with
+// SelfSubjectAccessReviews, and fails listing all the actions that are denied.
+func CheckAccess(ctx context.Context, actions []authorizationv1.ResourceAttributes) error {
+	clientset, err := NewClientset()
+	if err != nil {
+		return err
+	}
+
+	var denied []string
+	for i := range actions {
+		review := authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &actions[i]},
+		}
+
+		res, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &review, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to review access to %v, err: %v", describeAction(actions[i]), err)
+		}
+
+		if !res.Status.Allowed {
+			denied = append(denied, describeAction(actions[i]))
+		}
+	}
+
+	if len(denied) > 0 {
+		return fmt.Errorf("missing RBAC permissions:\n- %v", strings.Join(denied, "\n- "))
+	}
+
+	return nil
+}
+
+func describeAction(a authorizationv1.ResourceAttributes) string {
+	resource := a.Resource
+	if a.Group != "" {
+		resource += "." + a.Group
+	}
+
+	if a.Namespace == "" {
+		return fmt.Sprintf("%v %v", a.Verb, resource)
+	}
+
+	return fmt.Sprintf("%v %v in namespace %v", a.Verb, resource, a.Namespace)
+}
+
+// NamespacesMatching returns the names of the namespaces matching the given label selector.
+func NamespacesMatching(ctx context.Context, selector string) ([]string, error) {
+	clientset, err := NewClientset()
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces matching %q, err: %v", selector, err)
+	}
+
+	var names []string
+	for _, ns := range list.Items {
+		names = append(names, ns.Name)
+	}
+
+	return names, nil
+}
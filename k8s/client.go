@@ -0,0 +1,116 @@
+// Package k8s builds the Kubernetes clients shared by all bootstrap binaries.
+//
+// Inside a cluster, the in-cluster configuration of the Pod's service account is used. When a kubeconfig
+// or a context is given explicitly, or when the binary is not running inside a cluster, the configuration
+// is loaded from the kubeconfig instead, following the same rules as kubectl (--kubeconfig, KUBECONFIG,
+// ~/.kube/config).
+package k8s
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"sync"
+	"tyk/tyk/bootstrap/retry"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var (
+	kubeconfig  string
+	kubeContext string
+	retryPolicy = retry.DefaultPolicy()
+
+	once          sync.Once
+	config        *rest.Config
+	clientset     *kubernetes.Clientset
+	dynamicClient dynamic.Interface
+	initErr       error
+)
+
+// BindFlags registers the flags configuring the Kubernetes client on fs.
+func BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&kubeconfig, "kubeconfig", "",
+		"Path to a kubeconfig file, used to run outside of a Kubernetes cluster")
+	fs.StringVar(&kubeContext, "context", "",
+		"Name of the kubeconfig context to use")
+}
+
+// SetRetryPolicy sets the policy applied to all Kubernetes API calls. It must be called before any client
+// is created.
+func SetRetryPolicy(policy retry.Policy) {
+	retryPolicy = policy
+}
+
+// Config returns the configuration used to connect to the Kubernetes API.
+func Config() (*rest.Config, error) {
+	once.Do(func() {
+		config, initErr = loadConfig()
+		if initErr != nil {
+			return
+		}
+
+		config.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+			return &retry.Transport{Base: rt, Policy: retryPolicy}
+		}
+
+		clientset, initErr = kubernetes.NewForConfig(config)
+		if initErr != nil {
+			return
+		}
+
+		dynamicClient, initErr = dynamic.NewForConfig(config)
+	})
+
+	return config, initErr
+}
+
+// NewClientset returns a Kubernetes clientset. The clientset is created once and shared by all callers.
+func NewClientset() (*kubernetes.Clientset, error) {
+	if _, err := Config(); err != nil {
+		return nil, err
+	}
+
+	return clientset, nil
+}
+
+// NewDynamicClient returns a dynamic client, used for custom resources. The client is created once and shared
+// by all callers.
+func NewDynamicClient() (dynamic.Interface, error) {
+	if _, err := Config(); err != nil {
+		return nil, err
+	}
+
+	return dynamicClient, nil
+}
+
+// Namespace returns the namespace of the kubeconfig context in use, or "default" if the context does not
+// set one. It is meant to be used when running outside of a cluster, where the Pod namespace is unknown.
+func Namespace() (string, error) {
+	ns, _, err := clientConfig().Namespace()
+	return ns, err
+}
+
+func loadConfig() (*rest.Config, error) {
+	if kubeconfig == "" && kubeContext == "" {
+		c, err := rest.InClusterConfig()
+		if !errors.Is(err, rest.ErrNotInCluster) {
+			return c, err
+		}
+	}
+
+	return clientConfig().ClientConfig()
+}
+
+func clientConfig() clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		rules,
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+	)
+}
@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ApplyObject creates or updates the namespaced custom resource obj of the given resource with server-side
+// apply.
+func ApplyObject(ctx context.Context, resource schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+	client, err := NewDynamicClient()
+	if err != nil {
+		return err
+	}
+
+	patch, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	force := true
+	_, err = client.Resource(resource).Namespace(obj.GetNamespace()).
+		Patch(ctx, obj.GetName(), types.ApplyPatchType, patch, metav1.PatchOptions{FieldManager: fieldManager, Force: &force})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to apply %v/%v/%v, is its CustomResourceDefinition installed? err: %v",
+			resource.GroupResource(), obj.GetNamespace(), obj.GetName(), err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply %v/%v/%v, err: %v",
+			resource.GroupResource(), obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	return nil
+}
+
+// DeleteObjects deletes the custom resources of the given resource matching the label selector in
+// namespace, and returns their names. Nothing is deleted if the resource is not installed.
+func DeleteObjects(ctx context.Context, resource schema.GroupVersionResource, namespace,
+	selector string) ([]string, error) {
+	client, err := NewDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := client.Resource(resource).Namespace(namespace).
+		List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %v in namespace %v, err: %v", resource.GroupResource(), namespace, err)
+	}
+
+	var deleted []string
+	for _, item := range list.Items {
+		err = client.Resource(resource).Namespace(namespace).Delete(ctx, item.GetName(), metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete %v/%v/%v, err: %v",
+				resource.GroupResource(), namespace, item.GetName(), err)
+		}
+		deleted = append(deleted, item.GetName())
+	}
+
+	return deleted, nil
+}
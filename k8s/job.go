@@ -0,0 +1,106 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// PodNameEnvVar holds the name of the Pod the binary runs in, usually set through the downward API. If it
+	// is not set, the hostname is used, which matches the Pod name unless overridden in the Pod spec.
+	PodNameEnvVar = "TYK_POD_NAME"
+
+	eventSourceComponent = "tyk-k8s-bootstrap"
+)
+
+// CurrentJob returns the Job that owns the Pod this binary runs in.
+func CurrentJob(ctx context.Context, namespace string) (*batchv1.Job, error) {
+	clientset, err := NewClientset()
+	if err != nil {
+		return nil, err
+	}
+
+	podName := os.Getenv(PodNameEnvVar)
+	if podName == "" {
+		if podName, err = os.Hostname(); err != nil {
+			return nil, err
+		}
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod/%v/%v, err: %v", namespace, podName, err)
+	}
+
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "Job" {
+			return clientset.BatchV1().Jobs(namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		}
+	}
+
+	return nil, fmt.Errorf("pod/%v/%v is not owned by a Job", namespace, podName)
+}
+
+// WarnCurrentJob records a Warning Event with the given reason and message on the Job this binary runs in,
+// and adds the given annotations to the Job.
+func WarnCurrentJob(ctx context.Context, namespace, reason, message string, annotations map[string]string) error {
+	clientset, err := NewClientset()
+	if err != nil {
+		return err
+	}
+
+	job, err := CurrentJob(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	now := metav1.NewTime(time.Now())
+	event := corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: job.Name + "."},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+			Name:       job.Name,
+			Namespace:  job.Namespace,
+			UID:        job.UID,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           corev1.EventTypeWarning,
+		Source:         corev1.EventSource{Component: eventSourceComponent},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+
+	_, err = clientset.CoreV1().Events(namespace).Create(ctx, &event, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create event for job/%v/%v, err: %v", namespace, job.Name, err)
+	}
+
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = clientset.BatchV1().Jobs(namespace).Patch(ctx, job.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to annotate job/%v/%v, err: %v", namespace, job.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+// fieldManager is the field manager of the objects applied by the bootstrap binaries.
+const fieldManager = "tyk-k8s-bootstrap"
+
+// ApplySecret creates or updates secret with server-side apply, so that the Secret never goes missing while
+// it is updated, and labels, annotations and keys added by others are kept. Keys applied previously but
+// missing from secret are removed.
+func ApplySecret(ctx context.Context, secret *corev1.Secret) error {
+	clientset, err := NewClientset()
+	if err != nil {
+		return err
+	}
+
+	secretType := secret.Type
+	if secretType == "" {
+		secretType = corev1.SecretTypeOpaque
+	}
+
+	config := corev1ac.Secret(secret.Name, secret.Namespace).
+		WithLabels(secret.Labels).
+		WithAnnotations(secret.Annotations).
+		WithType(secretType).
+		WithData(secret.Data)
+
+	for _, owner := range secret.OwnerReferences {
+		ref := metav1ac.OwnerReference().
+			WithAPIVersion(owner.APIVersion).
+			WithKind(owner.Kind).
+			WithName(owner.Name).
+			WithUID(owner.UID)
+		if owner.BlockOwnerDeletion != nil {
+			ref.WithBlockOwnerDeletion(*owner.BlockOwnerDeletion)
+		}
+		config.WithOwnerReferences(ref)
+	}
+
+	_, err = clientset.CoreV1().Secrets(secret.Namespace).
+		Apply(ctx, config, metav1.ApplyOptions{FieldManager: fieldManager, Force: true})
+	if err != nil {
+		return fmt.Errorf("failed to apply secret/%v/%v, err: %v", secret.Namespace, secret.Name, err)
+	}
+
+	return nil
+}
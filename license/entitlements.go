@@ -0,0 +1,52 @@
+package license
+
+import "fmt"
+
+const (
+	ScopePortal   = "portal"
+	ScopeMDCB     = "mdcb"
+	ScopeOperator = "operator"
+)
+
+// Topology describes the Tyk components a chart installation is about to deploy.
+type Topology struct {
+	GatewayReplicas int
+	Portal          bool
+	MDCB            bool
+	Operator        bool
+}
+
+// CheckEntitlements compares the given topology with the entitlements of the license and returns a
+// description of every way the topology exceeds the license. Features are only checked if the license
+// lists its scopes at all.
+func (i *Info) CheckEntitlements(t Topology) []string {
+	var violations []string
+
+	if i.AllowedNodes > 0 && t.GatewayReplicas > i.AllowedNodes {
+		violations = append(violations, fmt.Sprintf(
+			"%d gateway replicas are requested but the license allows %d gateway nodes",
+			t.GatewayReplicas, i.AllowedNodes))
+	}
+
+	if len(i.Scopes) == 0 {
+		return violations
+	}
+
+	features := []struct {
+		enabled bool
+		scope   string
+		name    string
+	}{
+		{t.Portal, ScopePortal, "developer portal"},
+		{t.MDCB, ScopeMDCB, "MDCB"},
+		{t.Operator, ScopeOperator, "Tyk Operator"},
+	}
+	for _, f := range features {
+		if f.enabled && !i.HasScope(f.scope) {
+			violations = append(violations, fmt.Sprintf(
+				"%s is enabled but the license is not entitled to the %q scope", f.name, f.scope))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,45 @@
+package license
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/data"
+	"tyk/tyk/bootstrap/k8s"
+)
+
+const licenseExpiresSoonReason = "LicenseExpiresSoon"
+
+// CheckExpiry warns if the license expires within data.AppConfig.LicenseExpiryWarningThreshold. The warning
+// is printed, recorded as a Kubernetes Event on the Job running this binary and added to the Job as the
+// constants.TykLicenseExpiryWarningAnnotation annotation, without failing the Job. If
+// data.AppConfig.LicenseExpiryStrict is set, an error is returned instead.
+func CheckExpiry(ctx context.Context, info *Info) error {
+	remaining := info.RemainingValidity()
+	if remaining > data.AppConfig.LicenseExpiryWarningThreshold {
+		return nil
+	}
+
+	msg := fmt.Sprintf("Tyk Dashboard license expires in %v, at %v",
+		remaining.Round(time.Minute), info.ExpiresAt.Format(time.RFC3339))
+
+	if data.AppConfig.LicenseExpiryStrict {
+		return fmt.Errorf("%v, which is within the warning threshold of %v", msg,
+			data.AppConfig.LicenseExpiryWarningThreshold)
+	}
+
+	fmt.Printf("[WARNING] %v\n", msg)
+
+	if data.AppConfig.TykPodNamespace == "" {
+		return nil
+	}
+
+	err := k8s.WarnCurrentJob(ctx, data.AppConfig.TykPodNamespace, licenseExpiresSoonReason, msg,
+		map[string]string{constants.TykLicenseExpiryWarningAnnotation: info.ExpiresAt.Format(time.RFC3339)})
+	if err != nil {
+		fmt.Printf("[WARNING] Failed to report license expiry on the job, err: %v\n", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package license
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"strings"
+	"time"
+)
+
+// Info holds the claims of a Tyk Dashboard license.
+type Info struct {
+	Owner     string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	// AllowedNodes is the number of gateway nodes the license allows; 0 means the license does not limit
+	// the number of nodes.
+	AllowedNodes int
+	// Scopes lists the features the license is entitled to.
+	Scopes []string
+	// Verified reports whether the signature of the license was verified.
+	Verified bool
+}
+
+func newInfo(claims jwt.MapClaims) (*Info, error) {
+	exp, ok := numericClaim(claims["exp"])
+	if !ok {
+		return nil, errors.New("impossible to parse expiration date, license has no valid exp claim")
+	}
+
+	owner, _ := claims["owner"].(string)
+
+	info := &Info{
+		ExpiresAt: time.Unix(exp, 0),
+		Owner:     owner,
+	}
+
+	if iat, ok := numericClaim(claims["iat"]); ok {
+		info.IssuedAt = time.Unix(iat, 0)
+	}
+
+	if nodes, ok := numericClaim(claims["allowed_nodes"]); ok {
+		info.AllowedNodes = int(nodes)
+	} else {
+		info.AllowedNodes = len(listClaim(claims["allowed_nodes"]))
+	}
+
+	info.Scopes = append(listClaim(claims["scope"]), listClaim(claims["scopes"])...)
+
+	return info, nil
+}
+
+// HasScope reports whether the license is entitled to the given scope.
+func (i *Info) HasScope(scope string) bool {
+	for _, s := range i.Scopes {
+		if strings.EqualFold(s, scope) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (i *Info) String() string {
+	nodes := "unlimited"
+	if i.AllowedNodes > 0 {
+		nodes = fmt.Sprint(i.AllowedNodes)
+	}
+
+	scopes := "none"
+	if len(i.Scopes) > 0 {
+		scopes = strings.Join(i.Scopes, ", ")
+	}
+
+	return fmt.Sprintf("owner: %q, expires at: %v, allowed nodes: %v, scopes: %v, signature verified: %v",
+		i.Owner, i.ExpiresAt.Format(time.RFC3339), nodes, scopes, i.Verified)
+}
+
+func numericClaim(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return int64(f), err == nil
+	case float64:
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// listClaim reads a claim given either as a comma or space separated string or as a list of strings.
+func listClaim(v interface{}) []string {
+	var items []string
+
+	switch l := v.(type) {
+	case string:
+		items = strings.FieldsFunc(l, func(r rune) bool {
+			return r == ',' || r == ' '
+		})
+	case []interface{}:
+		for _, item := range l {
+			if s := strings.TrimSpace(fmt.Sprint(item)); s != "" {
+				items = append(items, s)
+			}
+		}
+	}
+
+	return items
+}
+
+// RemainingValidity returns the time left until the license expires.
+func (i *Info) RemainingValidity() time.Duration {
+	return time.Until(i.ExpiresAt)
+}
@@ -0,0 +1,81 @@
+package license
+
+import (
+	"crypto"
+	"crypto/x509"
+	_ "embed"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"tyk/tyk/bootstrap/constants"
+)
+
+// defaultPublicKey is the PEM encoded public key licenses are verified with, unless it is overridden
+// through constants.TykLicensePublicKeyEnvVar or constants.TykLicensePublicKeyFileEnvVar.
+//
+//go:embed public_key.pem
+var defaultPublicKey []byte
+
+// PublicKey returns the public key Tyk Dashboard licenses are verified with. The key is read from
+// constants.TykLicensePublicKeyEnvVar, or from the file given in constants.TykLicensePublicKeyFileEnvVar,
+// falling back to the embedded default key. It returns nil if none of them holds a key, which is the case
+// for builds that do not embed the Tyk public key in public_key.pem.
+func PublicKey() (crypto.PublicKey, error) {
+	if key := os.Getenv(constants.TykLicensePublicKeyEnvVar); key != "" {
+		pub, err := parsePublicKey([]byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %v, err: %v", constants.TykLicensePublicKeyEnvVar, err)
+		}
+
+		return pub, nil
+	}
+
+	if path := os.Getenv(constants.TykLicensePublicKeyFileEnvVar); path != "" {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read license public key file, err: %v", err)
+		}
+
+		pub, err := parsePublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse license public key file %v, err: %v", path, err)
+		}
+
+		return pub, nil
+	}
+
+	if block, _ := pem.Decode(defaultPublicKey); block == nil {
+		return nil, nil
+	}
+
+	pub, err := parsePublicKey(defaultPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse embedded license public key, err: %v", err)
+	}
+
+	return pub, nil
+}
+
+func parsePublicKey(key []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		return cert.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
@@ -1,46 +1,140 @@
 package license
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt"
-	"os"
-	"strconv"
 	"strings"
 	"time"
 	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/data"
 )
 
+// GetDashboardLicense returns the license read by data.InitAppDataPreInstall, from TYK_DB_LICENSEKEY or from
+// the file or Secret referenced by TYK_DB_LICENSEKEY_FILE or TYK_DB_LICENSEKEY_SECRET_REF.
 func GetDashboardLicense() (string, error) {
-	license, ok := os.LookupEnv(constants.TykDashboardLicenseEnvVarName)
-	if !ok {
-		return "", errors.New("license env var is not present")
-	}
-
+	license := data.AppConfig.DashBoardLicense
 	if license == "" {
-		return "", errors.New("empty dashboard license")
+		return "", fmt.Errorf("empty dashboard license, set %v, %v_FILE or %v_SECRET_REF",
+			constants.TykDashboardLicenseEnvVarName, constants.TykDashboardLicenseEnvVarName,
+			constants.TykDashboardLicenseEnvVarName)
 	}
 
 	return license, nil
 }
 
-func ValidateDashboardLicense(license string) (bool, error) {
-	token, _ := jwt.Parse(license, func(token *jwt.Token) (interface{}, error) {
-		return []byte(""), nil
+// ValidateDashboardLicense verifies the signature of the given Tyk Dashboard license with the key returned
+// by PublicKey and checks that the license has not expired. It returns the claims of the license. If no key
+// is configured, the signature cannot be verified, so a warning is printed and only the claims are checked.
+func ValidateDashboardLicense(license string) (*Info, error) {
+	key, err := PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil {
+		fmt.Printf("[WARNING] No license public key is configured, the license signature is not verified. Set "+
+			"%v or %v to the Tyk public key to verify it\n", constants.TykLicensePublicKeyEnvVar,
+			constants.TykLicensePublicKeyFileEnvVar)
+
+		return ParseUnverified(license)
+	}
+
+	return Parse(license, key)
+}
+
+// Parse parses the given Tyk Dashboard license and verifies its signature with key. The returned error
+// describes why the license was rejected; if the license is only rejected because it expired, its claims
+// are returned along with the error.
+func Parse(license string, key crypto.PublicKey) (*Info, error) {
+	if key == nil {
+		return nil, errors.New("no license public key given, the dashboard license signature cannot be verified")
+	}
+
+	methods, err := signingMethods(key)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := jwt.Parser{ValidMethods: methods, SkipClaimsValidation: true, UseJSONNumber: true}
+	claims := jwt.MapClaims{}
+
+	token, err := parser.ParseWithClaims(license, claims, func(*jwt.Token) (interface{}, error) {
+		return key, nil
 	})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return licenseInfo(token, claims, true)
+}
 
-	if strings.ToLower(fmt.Sprint(token.Header["typ"])) == "jwt" {
-		exp := strings.Split(fmt.Sprintf("%f", token.Claims.(jwt.MapClaims)["exp"]), ".")[0]
-		expDate, err := strconv.ParseInt(exp, 10, 64)
-		if err != nil {
-			return false, errors.New("impossible to parse expiration date")
-		}
-		if time.Unix(expDate, 0).Before(time.Now()) {
-			return false, errors.New("expired dashboard license")
-		}
-	} else {
-		return false, errors.New("token is not of jwt type")
+// ParseUnverified parses the given Tyk Dashboard license like Parse, without verifying its signature. It is
+// only meant to read the claims of a license that is verified elsewhere, or that cannot be verified.
+func ParseUnverified(license string) (*Info, error) {
+	parser := jwt.Parser{SkipClaimsValidation: true, UseJSONNumber: true}
+	claims := jwt.MapClaims{}
+
+	token, _, err := parser.ParseUnverified(license, claims)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return licenseInfo(token, claims, false)
+}
+
+// licenseInfo returns the claims of a parsed license token, along with an error if the license expired.
+func licenseInfo(token *jwt.Token, claims jwt.MapClaims, verified bool) (*Info, error) {
+	if typ := fmt.Sprint(token.Header["typ"]); !strings.EqualFold(typ, "jwt") {
+		return nil, fmt.Errorf("license token is of type %q, expected JWT", typ)
+	}
+
+	info, err := newInfo(claims)
+	if err != nil {
+		return nil, err
+	}
+	info.Verified = verified
+
+	if info.ExpiresAt.Before(time.Now()) {
+		return info, fmt.Errorf("expired dashboard license, it expired at %v", info.ExpiresAt.Format(time.RFC3339))
 	}
 
-	return true, nil
+	return info, nil
+}
+
+func parseError(err error) error {
+	var ve *jwt.ValidationError
+	if !errors.As(err, &ve) {
+		return fmt.Errorf("failed to parse dashboard license, err: %v", err)
+	}
+
+	switch {
+	case ve.Errors&jwt.ValidationErrorMalformed != 0:
+		return fmt.Errorf("malformed dashboard license, the license is not a valid JWT, err: %v", ve)
+	case ve.Errors&jwt.ValidationErrorSignatureInvalid != 0:
+		return fmt.Errorf("invalid dashboard license signature, the license may have been tampered with "+
+			"or truncated, err: %v", ve)
+	case ve.Errors&jwt.ValidationErrorUnverifiable != 0:
+		return fmt.Errorf("failed to verify dashboard license signature, err: %v", ve)
+	default:
+		return fmt.Errorf("invalid dashboard license, err: %v", ve)
+	}
+}
+
+// signingMethods returns the JWT signing methods that can be verified with the given key.
+func signingMethods(key crypto.PublicKey) ([]string, error) {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}, nil
+	case *ecdsa.PublicKey:
+		return []string{"ES256", "ES384", "ES512"}, nil
+	case ed25519.PublicKey:
+		return []string{"EdDSA"}, nil
+	default:
+		return nil, fmt.Errorf("unsupported license public key type %T", key)
+	}
 }
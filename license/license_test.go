@@ -0,0 +1,195 @@
+package license
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt"
+	"strings"
+	"testing"
+	"time"
+	"tyk/tyk/bootstrap/constants"
+)
+
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func signLicense(t *testing.T, key *rsa.PrivateKey, expiresAt time.Time) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"owner":         "tyk",
+		"exp":           expiresAt.Unix(),
+		"allowed_nodes": 3,
+		"scope":         []string{"multi_team", "portal"},
+	})
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestParse(t *testing.T) {
+	key := newTestKey(t)
+	otherKey := newTestKey(t)
+	valid := signLicense(t, key, time.Now().Add(24*time.Hour))
+	segments := strings.Split(valid, ".")
+
+	tests := []struct {
+		name    string
+		license string
+		wantErr string
+		// wantInfo reports whether the license claims are returned along with the error.
+		wantInfo bool
+	}{
+		{name: "valid signature", license: valid, wantInfo: true},
+		{
+			name:    "bad signature",
+			license: signLicense(t, otherKey, time.Now().Add(24*time.Hour)),
+			wantErr: "invalid dashboard license signature",
+		},
+		{
+			name:    "truncated signature",
+			license: valid[:len(valid)-4],
+			wantErr: "invalid dashboard license signature",
+		},
+		{
+			name:    "malformed token",
+			license: segments[0] + "." + segments[1],
+			wantErr: "malformed dashboard license",
+		},
+		{name: "not a token", license: "not-a-license", wantErr: "malformed dashboard license"},
+		{
+			name:     "expired token",
+			license:  signLicense(t, key, time.Now().Add(-time.Hour)),
+			wantErr:  "expired dashboard license",
+			wantInfo: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := Parse(tc.license, &key.PublicKey)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+
+			if !tc.wantInfo {
+				if info != nil {
+					t.Errorf("expected no license info, got %v", info)
+				}
+				return
+			}
+
+			if info == nil || info.Owner != "tyk" || info.AllowedNodes != 3 || !info.HasScope("portal") {
+				t.Errorf("unexpected license info %v", info)
+			}
+		})
+	}
+}
+
+func TestParseRequiresKey(t *testing.T) {
+	license := signLicense(t, newTestKey(t), time.Now().Add(time.Hour))
+
+	if _, err := Parse(license, nil); err == nil {
+		t.Fatal("expected a license to be rejected without a public key")
+	}
+}
+
+func TestValidateDashboardLicense(t *testing.T) {
+	key := newTestKey(t)
+	license := signLicense(t, key, time.Now().Add(time.Hour))
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(constants.TykLicensePublicKeyEnvVar,
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+
+	info, err := ValidateDashboardLicense(license)
+	if err != nil || !info.Verified {
+		t.Fatalf("expected a verified license, got %v and error %v", info, err)
+	}
+
+	t.Setenv(constants.TykLicensePublicKeyEnvVar,
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: otherDER(t)})))
+
+	if _, err := ValidateDashboardLicense(license); err == nil {
+		t.Fatal("expected a license signed with another key to be rejected")
+	}
+
+	// Without a configured key, the signature is skipped with a warning and only the claims are checked.
+	t.Setenv(constants.TykLicensePublicKeyEnvVar, "")
+
+	info, err = ValidateDashboardLicense(license)
+	if err != nil || info.Verified {
+		t.Fatalf("expected an unverified license, got %v and error %v", info, err)
+	}
+
+	if _, err := ValidateDashboardLicense("not-a-license"); err == nil {
+		t.Fatal("expected a malformed license to be rejected without a public key")
+	}
+}
+
+func otherDER(t *testing.T) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(&newTestKey(t).PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return der
+}
+
+func TestParseUnverified(t *testing.T) {
+	key := newTestKey(t)
+
+	tests := []struct {
+		name     string
+		license  string
+		wantErr  string
+		wantInfo bool
+	}{
+		{name: "valid", license: signLicense(t, key, time.Now().Add(time.Hour)), wantInfo: true},
+		{name: "malformed", license: "not-a-license", wantErr: "malformed dashboard license"},
+		{
+			name:     "expired",
+			license:  signLicense(t, key, time.Now().Add(-time.Hour)),
+			wantErr:  "expired dashboard license",
+			wantInfo: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := ParseUnverified(tc.license)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+			if tc.wantInfo && (info == nil || info.Verified || info.Owner != "tyk") {
+				t.Errorf("unexpected license info %v", info)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Package portal implements a small typed client for the API of the Tyk Enterprise Developer Portal.
+//
+// The Client itself does not carry any credentials. It is only used to check that the portal is up and to
+// create its first admin user, which returns the admin's API token. All other calls are made through an
+// AdminClient, obtained via Client.Admin, which authenticates with that token.
+package portal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"tyk/tyk/bootstrap/apiclient"
+)
+
+const (
+	authHeader = "Authorization"
+
+	bootstrapEndpoint           = "/portal-api/bootstrap"
+	providersEndpoint           = "/portal-api/providers"
+	providerEndpoint            = "/portal-api/providers/%d"
+	providerSynchronizeEndpoint = "/portal-api/providers/%d/synchronize"
+	rootEndpoint                = "/"
+)
+
+// Client is a Tyk Enterprise Developer Portal API client.
+type Client struct {
+	api *apiclient.Client
+}
+
+// NewClient returns a Client sending requests to the portal reachable at url. If httpClient is nil,
+// http.DefaultClient is used.
+func NewClient(url string, httpClient *http.Client) *Client {
+	return &Client{api: apiclient.New(service, url, httpClient)}
+}
+
+// URL returns the base URL of the portal.
+func (c *Client) URL() string {
+	return c.api.URL()
+}
+
+// Ping checks that the portal answers HTTP requests. Any response below 500 means that the portal is up,
+// as its pages may require a login or redirect to the setup of the portal.
+func (c *Client) Ping(ctx context.Context) error {
+	err := c.do(ctx, http.MethodGet, rootEndpoint, "", nil, nil)
+	if code := StatusCode(err); code != 0 && code < 500 {
+		return nil
+	}
+
+	return err
+}
+
+// Bootstrap creates the first admin user of a portal that has not been bootstrapped yet, and returns the
+// API token of the admin.
+func (c *Client) Bootstrap(ctx context.Context, req BootstrapRequest) (string, error) {
+	res := BootstrapResponse{}
+	if err := c.do(ctx, http.MethodPost, bootstrapEndpoint, "", req, &res); err != nil {
+		return "", err
+	}
+
+	if res.Data.APIToken == "" {
+		return "", fmt.Errorf("portal responded to POST %s without an API token", bootstrapEndpoint)
+	}
+
+	return res.Data.APIToken, nil
+}
+
+// Admin returns a client authenticating against the portal API with the given admin API token.
+func (c *Client) Admin(token string) *AdminClient {
+	return &AdminClient{client: c, token: token}
+}
+
+// AdminClient sends requests authenticated with the API token of a portal admin.
+type AdminClient struct {
+	client *Client
+	token  string
+}
+
+func (a *AdminClient) do(ctx context.Context, method, path string, in, out interface{}) error {
+	return a.client.do(ctx, method, path, a.token, in, out)
+}
+
+// ListProviders returns the providers the portal publishes the APIs of.
+func (a *AdminClient) ListProviders(ctx context.Context) ([]Provider, error) {
+	var res []Provider
+	if err := a.do(ctx, http.MethodGet, providersEndpoint, nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CreateProvider creates a provider and returns it with its ID.
+func (a *AdminClient) CreateProvider(ctx context.Context, provider Provider) (Provider, error) {
+	res := Provider{}
+	if err := a.do(ctx, http.MethodPost, providersEndpoint, provider, &res); err != nil {
+		return Provider{}, err
+	}
+
+	return res, nil
+}
+
+// UpdateProvider updates the provider with the given ID.
+func (a *AdminClient) UpdateProvider(ctx context.Context, providerID int, provider Provider) error {
+	return a.do(ctx, http.MethodPut, fmt.Sprintf(providerEndpoint, providerID), provider, nil)
+}
+
+// SynchronizeProvider imports the policies and APIs of the provider with the given ID into the portal.
+func (a *AdminClient) SynchronizeProvider(ctx context.Context, providerID int) error {
+	return a.do(ctx, http.MethodPut, fmt.Sprintf(providerSynchronizeEndpoint, providerID), nil, nil)
+}
+
+// do sends a request to the portal, authenticated with token unless it is empty, see apiclient.Client.Do.
+func (c *Client) do(ctx context.Context, method, path, token string, in, out interface{}) error {
+	return c.api.Do(ctx, method, path, authHeader, token, in, out)
+}
@@ -0,0 +1,174 @@
+package portal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		status  int
+		wantErr bool
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusFound},
+		{status: http.StatusUnauthorized},
+		{status: http.StatusNotFound},
+		{status: http.StatusInternalServerError, wantErr: true},
+		{status: http.StatusBadGateway, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.status == http.StatusFound {
+					// The portal redirects to its login page, which answers.
+					if r.URL.Path == "/" {
+						http.Redirect(w, r, "/auth/login", tc.status)
+					}
+					return
+				}
+				w.WriteHeader(tc.status)
+			}))
+			defer srv.Close()
+
+			err := NewClient(srv.URL, nil).Ping(context.Background())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantToken string
+		wantErr   string
+	}{
+		{name: "token", body: `{"code":"OK","data":{"api_token":"token"}}`, wantToken: "token"},
+		{name: "no token", body: `{"code":"OK","data":{}}`, wantErr: "portal responded to POST " +
+			bootstrapEndpoint + " without an API token"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var req BootstrapRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != bootstrapEndpoint || r.Header.Get(authHeader) != "" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				fmt.Fprint(w, tc.body)
+			}))
+			defer srv.Close()
+
+			token, err := NewClient(srv.URL, nil).Bootstrap(context.Background(),
+				BootstrapRequest{Username: "admin@example.com", Password: "secret"})
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Errorf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil || token != tc.wantToken {
+				t.Errorf("expected token %q, got %q and error %v", tc.wantToken, token, err)
+			}
+			if req.Username != "admin@example.com" || req.Password != "secret" {
+				t.Errorf("unexpected bootstrap request %+v", req)
+			}
+		})
+	}
+}
+
+func TestProviders(t *testing.T) {
+	var requests []string
+	var received []Provider
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get(authHeader) != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.Method == http.MethodPost || r.URL.Path == fmt.Sprintf(providerEndpoint, 3) {
+			var provider Provider
+			if err := json.NewDecoder(r.Body).Decode(&provider); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			received = append(received, provider)
+		}
+
+		switch {
+		case r.Method == http.MethodGet:
+			fmt.Fprint(w, `[{"ID":3,"Name":"tyk","Type":"tyk-pro"}]`)
+		case r.Method == http.MethodPost:
+			fmt.Fprint(w, `{"ID":4,"Name":"other","Type":"tyk-pro"}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	admin := NewClient(srv.URL, nil).Admin("token")
+
+	providers, err := admin.ListProviders(ctx)
+	if err != nil || len(providers) != 1 || providers[0].ID != 3 || providers[0].Name != "tyk" {
+		t.Fatalf("unexpected providers %+v, err: %v", providers, err)
+	}
+
+	created, err := admin.CreateProvider(ctx, Provider{Name: "other", Type: ProviderTypeTykPro})
+	if err != nil || created.ID != 4 {
+		t.Fatalf("expected the created provider to be returned with its ID, got %+v, err: %v", created, err)
+	}
+
+	err = admin.UpdateProvider(ctx, 3, Provider{Name: "tyk", Type: ProviderTypeTykPro})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = admin.SynchronizeProvider(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[GET /portal-api/providers POST /portal-api/providers PUT /portal-api/providers/3 " +
+		"PUT /portal-api/providers/3/synchronize]"
+	if fmt.Sprint(requests) != want {
+		t.Errorf("expected requests %v, got %v", want, requests)
+	}
+	if len(received) != 2 || received[0].Name != "other" || received[1].Name != "tyk" {
+		t.Errorf("unexpected providers sent %+v", received)
+	}
+}
+
+func TestError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"Status":"Error","Message":"invalid token"}`)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, nil).Admin("token").ListProviders(context.Background())
+
+	want := "portal responded to GET /portal-api/providers with status 401: invalid token"
+	if err == nil || err.Error() != want {
+		t.Errorf("expected error %q, got %v", want, err)
+	}
+	if code := StatusCode(err); code != http.StatusUnauthorized {
+		t.Errorf("expected status code %d, got %d", http.StatusUnauthorized, code)
+	}
+	if code := StatusCode(fmt.Errorf("connection refused")); code != 0 {
+		t.Errorf("expected no status code for other errors, got %d", code)
+	}
+}
@@ -0,0 +1,15 @@
+package portal
+
+import "tyk/tyk/bootstrap/apiclient"
+
+// service names the portal in errors.
+const service = "portal"
+
+// Error is returned for every portal response with a non-2xx status code.
+type Error = apiclient.Error
+
+// StatusCode returns the HTTP status code of the portal response that caused err, or 0 if err was not
+// caused by a non-2xx portal response.
+func StatusCode(err error) int {
+	return apiclient.StatusCode(err, service)
+}
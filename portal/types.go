@@ -0,0 +1,44 @@
+package portal
+
+// ProviderTypeTykPro is the type of the providers backed by a Tyk Dashboard.
+const ProviderTypeTykPro = "tyk-pro"
+
+// BootstrapRequest describes the first admin user of the portal.
+type BootstrapRequest struct {
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+type BootstrapResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		APIToken string `json:"api_token"`
+	} `json:"data"`
+}
+
+// Provider is a source of the APIs and policies published by the portal, such as a Tyk Dashboard.
+type Provider struct {
+	ID            int                   `json:"ID,omitempty"`
+	Name          string                `json:"Name"`
+	Type          string                `json:"Type"`
+	Configuration ProviderConfiguration `json:"Configuration"`
+}
+
+// ProviderConfiguration holds the settings of a provider, whose MetaData is a JSON encoded
+// ProviderMetaData for providers of type ProviderTypeTykPro.
+type ProviderConfiguration struct {
+	MetaData string `json:"MetaData"`
+}
+
+// ProviderMetaData tells the portal how to connect to a Tyk Dashboard, with the access key of a user of
+// the organisation OrgID.
+type ProviderMetaData struct {
+	URL                string `json:"URL"`
+	Secret             string `json:"Secret"`
+	OrgID              string `json:"OrgID"`
+	Gateway            string `json:"Gateway,omitempty"`
+	InsecureSkipVerify bool   `json:"InsecureSkipVerify"`
+}
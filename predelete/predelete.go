@@ -2,34 +2,34 @@ package predelete
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"strings"
 	"tyk/tyk/bootstrap/constants"
 	"tyk/tyk/bootstrap/data"
+	"tyk/tyk/bootstrap/helpers"
+	"tyk/tyk/bootstrap/k8s"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 )
 
 // maybe not needed?
 func ExecutePreDeleteOperations() error {
-	config, err := rest.InClusterConfig()
+	clientset, err := k8s.NewClientset()
 	if err != nil {
 		return err
 	}
 
-	clientset, err := kubernetes.NewForConfig(config)
+	err = PreDeleteBootstrapSecrets(clientset)
 	if err != nil {
 		return err
 	}
 
-	err = PreDeleteOperatorSecret(clientset)
-	if err != nil {
-		return err
-	}
-
-	err = PreDeletePortalSecret(clientset)
+	err = PreDeleteOperatorContexts()
 	if err != nil {
 		return err
 	}
@@ -42,60 +42,142 @@ func ExecutePreDeleteOperations() error {
 	return nil
 }
 
-func PreDeleteOperatorSecret(clientset *kubernetes.Clientset) error {
-	fmt.Println("Running pre delete hook")
-	secrets, err := clientset.CoreV1().Secrets(os.Getenv("TYK_POD_NAMESPACE")).List(context.TODO(), metav1.ListOptions{})
+// PreDeleteOperatorContexts deletes the OperatorContexts generated by the post install hook, found by their
+// labels in data.AppConfig.OperatorContextNamespaces.
+func PreDeleteOperatorContexts() error {
+	selector, err := bootstrapSelector(constants.TykBootstrapOperatorContextLabel)
 	if err != nil {
 		return err
 	}
 
-	found := false
-	for _, value := range secrets.Items {
-		if value.Name == os.Getenv("OPERATOR_SECRET_NAME") {
-			err = clientset.CoreV1().Secrets(os.Getenv("TYK_POD_NAMESPACE")).Delete(context.TODO(), value.Name, metav1.DeleteOptions{})
-			if err != nil {
-				return err
-			}
-			found = true
-			break
+	seen := map[string]bool{}
+	var errs []string
+	for _, ns := range data.AppConfig.OperatorContextNamespaces {
+		if seen[ns] {
+			continue
+		}
+		seen[ns] = true
+
+		deleted, err := k8s.DeleteObjects(context.TODO(), helpers.OperatorContextResource, ns, selector.String())
+		for _, name := range deleted {
+			fmt.Printf("A previously created operator context %v/%v was identified and deleted\n", ns, name)
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
 		}
 	}
 
-	if !found {
-		fmt.Println("A previously created operator secret has not been identified")
-	} else {
-		fmt.Println("A previously created operator secret was identified and deleted")
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
 	}
+
 	return nil
 }
 
-func PreDeletePortalSecret(clientset *kubernetes.Clientset) error {
+// bootstrapSelector selects the objects generated by the post install hook of the current release whose
+// constants.TykBootstrapLabel label is one of components.
+func bootstrapSelector(components ...string) (labels.Selector, error) {
+	requirement, err := labels.NewRequirement(constants.TykBootstrapLabel, selection.In, components)
+	if err != nil {
+		return nil, err
+	}
+	selector := labels.NewSelector().Add(*requirement)
+
+	if data.AppConfig.HelmReleaseName != "" {
+		instance, err := labels.NewRequirement(constants.AppInstanceLabel, selection.Equals,
+			[]string{data.AppConfig.HelmReleaseName})
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*instance)
+	}
+
+	return selector, nil
+}
+
+// PreDeleteBootstrapSecrets deletes the operator and portal secrets, found by their labels in the Tyk
+// namespace and in the namespaces of data.AppConfig.SecretTargets. Secrets named OPERATOR_SECRET_NAME and
+// DEVELOPER_PORTAL_SECRET_NAME in the Tyk namespace are deleted as well, as the Secrets generated by
+// earlier versions have no labels. Failures in one namespace do not prevent the cleanup of the others.
+//
+// The admin credentials and Enterprise Developer Portal credentials secrets are kept on purpose: they hold
+// the only copy of generated passwords and of the access keys needed to adopt the organisations of a
+// Dashboard whose database outlives the release.
+func PreDeleteBootstrapSecrets(clientset *kubernetes.Clientset) error {
 	fmt.Println("Running pre delete hook")
-	ns := data.AppConfig.TykPodNamespace
 
-	secrets, err := clientset.CoreV1().Secrets(ns).
-		List(context.TODO(), metav1.ListOptions{})
+	selector, err := bootstrapSelector(constants.TykBootstrapOperatorSecretLabel,
+		constants.TykBootstrapPortalSecretLabel)
 	if err != nil {
 		return err
 	}
 
-	notFound := true
-	for _, value := range secrets.Items {
-		if data.AppConfig.DeveloperPortalSecretName == value.Name {
-			err = clientset.CoreV1().Secrets(ns).
-				Delete(context.TODO(), value.Name, metav1.DeleteOptions{})
+	namespaces := []string{data.AppConfig.TykPodNamespace}
+	var errs []string
+	for _, target := range data.AppConfig.SecretTargets {
+		resolved, err := target.ResolveNamespaces(context.TODO())
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		namespaces = append(namespaces, resolved...)
+	}
+
+	seenNamespaces := map[string]bool{}
+	deleted := 0
+	for _, ns := range namespaces {
+		if seenNamespaces[ns] {
+			continue
+		}
+		seenNamespaces[ns] = true
 
+		secrets, err := clientset.CoreV1().Secrets(ns).
+			List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to list secrets in namespace %v, err: %v", ns, err))
+			continue
+		}
+
+		var names []string
+		for _, secret := range secrets.Items {
+			names = append(names, secret.Name)
+		}
+		if ns == data.AppConfig.TykPodNamespace {
+			names = append(names, data.AppConfig.OperatorSecretName, data.AppConfig.DeveloperPortalSecretName)
+		}
+
+		seen := map[string]bool{}
+		for _, name := range names {
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			err = clientset.CoreV1().Secrets(ns).Delete(context.TODO(), name, metav1.DeleteOptions{})
+			if apierrors.IsNotFound(err) {
+				continue
+			}
 			if err != nil {
-				return err
+				errs = append(errs, fmt.Sprintf("failed to delete secret %v/%v, err: %v", ns, name, err))
+				continue
 			}
-			fmt.Println("A previously created developer portal secret was identified and deleted")
-			notFound = false
-			break
+
+			fmt.Printf("A previously created secret %v/%v was identified and deleted\n", ns, name)
+			deleted++
 		}
 	}
 
-	if notFound {
-		fmt.Println("A previously created developer portal secret has not been identified")
+	if deleted == 0 && len(errs) == 0 {
+		fmt.Println("No previously created operator or developer portal secrets have been identified")
+	}
+
+	fmt.Printf("The admin and enterprise portal credentials secrets are kept, delete them with kubectl delete "+
+		"secret -n %v -l '%v in (%v,%v)' once they are no longer needed\n", data.AppConfig.TykPodNamespace,
+		constants.TykBootstrapLabel, constants.TykBootstrapAdminCredentialsLabel,
+		constants.TykBootstrapEnterprisePortalCredentialsLabel)
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
 	}
 
 	return nil
@@ -1,10 +1,15 @@
 // Package preinstallation exposes an API to run necessary operations required in pre-install hook job of bootstrapping.
 // While bootstrapping Tyk Stack, users need to provide a valida Tyk License key.
-// In the pre-hook installation, the helper functions defined in this package verifies the validity of the license.
+// In the pre-hook installation, the helper functions defined in this package verifies the validity of the license
+// and checks that the license is entitled to the components the chart is about to deploy.
 package preinstallation
 
 import (
-	"errors"
+	"context"
+	"fmt"
+	"strings"
+	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/data"
 	"tyk/tyk/bootstrap/license"
 )
 
@@ -15,14 +20,44 @@ func PreHookInstall() error {
 		return err
 	}
 
-	licenseIsValid, err := license.ValidateDashboardLicense(dashboardLicenseKey)
+	info, err := license.ValidateDashboardLicense(dashboardLicenseKey)
 	if err != nil {
 		return err
 	}
 
-	if !licenseIsValid {
-		return errors.New("provided license is invalid")
+	fmt.Printf("License details: %v\n", info)
+
+	err = license.CheckExpiry(context.TODO(), info)
+	if err != nil {
+		return err
+	}
+
+	return CheckLicenseEntitlements(info)
+}
+
+// CheckLicenseEntitlements compares the license with the topology described by data.AppConfig. Violations
+// are reported as warnings, or as an error if data.AppConfig.LicenseEntitlementsStrict is set.
+func CheckLicenseEntitlements(info *license.Info) error {
+	violations := info.CheckEntitlements(license.Topology{
+		GatewayReplicas: data.AppConfig.GatewayReplicas,
+		Portal:          data.AppConfig.BootstrapPortal,
+		MDCB:            data.AppConfig.MdcbEnabled,
+		Operator:        data.AppConfig.OperatorSecretEnabled,
+	})
+	if len(violations) == 0 {
+		return nil
+	}
+
+	if data.AppConfig.LicenseEntitlementsStrict {
+		return fmt.Errorf("the installation exceeds the license entitlements:\n- %v",
+			strings.Join(violations, "\n- "))
+	}
+
+	for _, violation := range violations {
+		fmt.Printf("[WARNING] %v\n", violation)
 	}
+	fmt.Printf("[WARNING] The installation exceeds the license entitlements, set %v to true to fail instead\n",
+		constants.LicenseEntitlementsStrictEnvVar)
 
 	return nil
 }
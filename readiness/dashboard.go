@@ -0,0 +1,119 @@
+package readiness
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/dashboard"
+	"tyk/tyk/bootstrap/data"
+	"tyk/tyk/bootstrap/retry"
+)
+
+const (
+	dashboardInitialBackoff = time.Second
+	dashboardMaxBackoff     = 15 * time.Second
+)
+
+// WaitForDashboard probes the Dashboard API until it serves requests, for at most
+// data.AppConfig.DashboardHealthTimeout. The unauthenticated /hello endpoint must answer, and the admin
+// secret must be accepted by the Admin API. Connection errors and 5xx responses are retried with backoff,
+// while TLS errors and a rejected admin secret fail immediately, since retrying does not resolve them. Each
+// probe is sent once, as the probes are retried here rather than by the retry.Transport of client.
+func WaitForDashboard(ctx context.Context, client *dashboard.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, data.AppConfig.DashboardHealthTimeout)
+	defer cancel()
+	ctx = retry.WithoutRetry(ctx)
+
+	backoff := dashboardInitialBackoff
+	for {
+		err := probeDashboard(ctx, client)
+		if err == nil {
+			return nil
+		}
+
+		var fatal *fatalProbeError
+		if errors.As(err, &fatal) {
+			return fatal.err
+		}
+
+		fmt.Printf("Dashboard API at %v is not ready yet, retrying in %v: %v\n", client.URL(), backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("dashboard API at %v did not become ready in %v, last error: %v",
+				client.URL(), data.AppConfig.DashboardHealthTimeout, err)
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > dashboardMaxBackoff {
+			backoff = dashboardMaxBackoff
+		}
+	}
+}
+
+// fatalProbeError wraps errors of the Dashboard health check that are not retried.
+type fatalProbeError struct {
+	err error
+}
+
+func (e *fatalProbeError) Error() string {
+	return e.err.Error()
+}
+
+func probeDashboard(ctx context.Context, client *dashboard.Client) error {
+	hello, err := client.Hello(ctx)
+	if err != nil {
+		return classifyProbeError(client, err)
+	}
+
+	_, err = client.Admin(data.AppConfig.TykAdminSecret).ListOrganisations(ctx)
+	if err != nil {
+		return classifyProbeError(client, err)
+	}
+
+	fmt.Printf("Dashboard API at %v is ready, version: %v\n", client.URL(), hello.Version)
+
+	return nil
+}
+
+func classifyProbeError(client *dashboard.Client, err error) error {
+	switch code := dashboard.StatusCode(err); {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return &fatalProbeError{fmt.Errorf("dashboard rejected the admin secret, check %v, err: %v",
+			constants.TykAdminSecretEnvVar, err)}
+	case code >= 500 || code == http.StatusTooManyRequests:
+		return err
+	case code != 0:
+		return &fatalProbeError{fmt.Errorf("unexpected dashboard response, err: %v", err)}
+	}
+
+	if isTLSError(err) {
+		return &fatalProbeError{fmt.Errorf("TLS error while connecting to dashboard at %v, check %v, %v and %v, err: %v",
+			client.URL(), constants.TykDashboardProtoEnvVar, constants.TykDashboardCACertEnvVar,
+			constants.TykDashboardInsecureSkipVerify, err)}
+	}
+
+	// Connection refused, DNS and timeout errors are expected while the Dashboard is starting.
+	return err
+}
+
+func isTLSError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	var recordHeader tls.RecordHeaderError
+
+	return errors.As(err, &unknownAuthority) ||
+		errors.As(err, &hostname) ||
+		errors.As(err, &invalid) ||
+		errors.As(err, &recordHeader) ||
+		strings.Contains(err.Error(), "tls: ") ||
+		strings.Contains(err.Error(), "server gave HTTP response to HTTPS client")
+}
@@ -0,0 +1,50 @@
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"tyk/tyk/bootstrap/constants"
+	"tyk/tyk/bootstrap/data"
+	"tyk/tyk/bootstrap/portal"
+	"tyk/tyk/bootstrap/retry"
+)
+
+// WaitForEnterprisePortal probes the Tyk Enterprise Developer Portal until it answers HTTP requests, for at
+// most data.AppConfig.EnterprisePortalHealthTimeout. Connection errors and 5xx responses are retried with
+// the backoff of WaitForDashboard, while TLS errors fail immediately. Like the Dashboard probes, each probe
+// is sent once.
+func WaitForEnterprisePortal(ctx context.Context, client *portal.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, data.AppConfig.EnterprisePortalHealthTimeout)
+	defer cancel()
+	ctx = retry.WithoutRetry(ctx)
+
+	backoff := dashboardInitialBackoff
+	for {
+		err := client.Ping(ctx)
+		if err == nil {
+			fmt.Printf("Enterprise portal at %v is ready\n", client.URL())
+			return nil
+		}
+
+		if isTLSError(err) {
+			return fmt.Errorf("TLS error while connecting to enterprise portal at %v, check %v, %v and %v, "+
+				"err: %v", client.URL(), constants.EnterprisePortalUrlEnvVar, constants.EnterprisePortalCACertEnvVar,
+				constants.EnterprisePortalInsecureSkipVerifyEnvVar, err)
+		}
+
+		fmt.Printf("Enterprise portal at %v is not ready yet, retrying in %v: %v\n", client.URL(), backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("enterprise portal at %v did not become ready in %v, last error: %v",
+				client.URL(), data.AppConfig.EnterprisePortalHealthTimeout, err)
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > dashboardMaxBackoff {
+			backoff = dashboardMaxBackoff
+		}
+	}
+}
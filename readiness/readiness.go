@@ -2,71 +2,75 @@ package readiness
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	v1 "k8s.io/api/core/v1"
-	"strings"
-	"time"
 	"tyk/tyk/bootstrap/data"
+	"tyk/tyk/bootstrap/k8s"
+)
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
+const (
+	KindPod         = "Pod"
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
 )
 
-func CheckIfRequiredDeploymentsAreReady() error {
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		return err
-	}
+// Target describes workloads that must be ready before bootstrapping continues. Workloads are either
+// selected by Selector, a label selector, or by Name.
+type Target struct {
+	Kind     string
+	Selector string
+	Name     string
+	// Optional targets that do not match any workload are ready, e.g. the Redis Pods, as Redis may be
+	// deployed outside the namespace.
+	Optional bool
+}
 
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return err
+func (t Target) String() string {
+	if t.Name != "" {
+		return fmt.Sprintf("%v/%v", t.Kind, t.Name)
 	}
 
-	time.Sleep(5 * time.Second)
-
-	var attemptCount int
-	for {
-		attemptCount++
-		if attemptCount > 180 {
-			return errors.New("attempted readiness check too many times")
-		}
-		pods, err := clientset.CoreV1().Pods(data.AppConfig.TykPodNamespace).
-			List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			return err
-		}
-		fmt.Printf("There are %d other pods in the cluster\n", len(pods.Items)-1)
+	return fmt.Sprintf("%vs matching %q", t.Kind, t.Selector)
+}
 
-		var requiredPods []v1.Pod
-		for _, pod := range pods.Items {
-			if strings.Contains(pod.Name, "dashboard") ||
-				strings.Contains(pod.Name, "redis") {
-				requiredPods = append(requiredPods, pod)
-			}
-		}
+// Targets returns the readiness targets configured in data.AppConfig.
+func Targets() []Target {
+	var targets []Target
 
-		notReadyPods := make(map[string]struct{})
-		for _, pod := range requiredPods {
-			podStatus := pod.Status
-			for container := range pod.Spec.Containers {
-				if !podStatus.ContainerStatuses[container].Ready {
-					notReadyPods[pod.Name] = struct{}{}
-				}
-			}
-		}
+	for _, selector := range data.AppConfig.ReadinessPodSelectors {
+		targets = append(targets, Target{Kind: KindPod, Selector: selector})
+	}
+	for _, selector := range data.AppConfig.ReadinessOptionalPodSelectors {
+		targets = append(targets, Target{Kind: KindPod, Selector: selector, Optional: true})
+	}
+	for _, selector := range data.AppConfig.ReadinessDeploymentSelectors {
+		targets = append(targets, Target{Kind: KindDeployment, Selector: selector})
+	}
+	for _, name := range data.AppConfig.ReadinessDeployments {
+		targets = append(targets, Target{Kind: KindDeployment, Name: name})
+	}
+	for _, name := range data.AppConfig.ReadinessStatefulSets {
+		targets = append(targets, Target{Kind: KindStatefulSet, Name: name})
+	}
 
-		if len(notReadyPods) == 0 {
-			return nil
-		}
+	return targets
+}
 
-		fmt.Printf("The following pods have containers that are NOT ready: ")
-		for pod, _ := range notReadyPods {
-			fmt.Println(pod)
-		}
+// CheckIfRequiredDeploymentsAreReady waits for all readiness targets configured in data.AppConfig to be
+// ready, for at most data.AppConfig.ReadinessTimeout.
+func CheckIfRequiredDeploymentsAreReady() error {
+	targets := Targets()
+	if len(targets) == 0 {
+		fmt.Println("No readiness targets are configured, skipping readiness check")
+		return nil
+	}
 
-		time.Sleep(2 * time.Second)
+	clientset, err := k8s.NewClientset()
+	if err != nil {
+		return err
 	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), data.AppConfig.ReadinessTimeout)
+	defer cancel()
+
+	return WaitForTargets(ctx, clientset, data.AppConfig.TykPodNamespace, targets)
 }
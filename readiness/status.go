@@ -0,0 +1,88 @@
+package readiness
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// podStatus reports whether the given Pod is ready, and if not, why. Container statuses are matched by
+// name, as they are not populated until the Pod is scheduled.
+func podStatus(pod *v1.Pod) (bool, string) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady && condition.Status == v1.ConditionTrue {
+			return true, ""
+		}
+	}
+
+	ready := 0
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Ready {
+			ready++
+		}
+	}
+
+	return false, fmt.Sprintf("is %v, %d of %d containers ready", pod.Status.Phase, ready, len(pod.Spec.Containers))
+}
+
+// DeploymentRolloutStatus reports whether the rollout of the given Deployment is complete, and if not, why.
+// It follows the same rules as `kubectl rollout status`.
+func DeploymentRolloutStatus(deployment *appsv1.Deployment) (bool, string) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return false, "waiting for rollout to be observed"
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	status := deployment.Status
+	switch {
+	case status.UpdatedReplicas < replicas:
+		return false, fmt.Sprintf("%d of %d replicas updated", status.UpdatedReplicas, replicas)
+	case status.Replicas > status.UpdatedReplicas:
+		return false, fmt.Sprintf("%d old replicas pending termination", status.Replicas-status.UpdatedReplicas)
+	case status.AvailableReplicas < status.UpdatedReplicas:
+		return false, fmt.Sprintf("%d of %d updated replicas available", status.AvailableReplicas, status.UpdatedReplicas)
+	}
+
+	return true, ""
+}
+
+// StatefulSetRolloutStatus reports whether the rollout of the given StatefulSet is complete, and if not, why.
+// It follows the same rules as `kubectl rollout status`.
+func StatefulSetRolloutStatus(sts *appsv1.StatefulSet) (bool, string) {
+	if sts.Generation > sts.Status.ObservedGeneration {
+		return false, "waiting for rollout to be observed"
+	}
+
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+
+	status := sts.Status
+	if status.ReadyReplicas < replicas {
+		return false, fmt.Sprintf("%d of %d replicas ready", status.ReadyReplicas, replicas)
+	}
+
+	if sts.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return true, ""
+	}
+
+	if rollingUpdate := sts.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+		if status.UpdatedReplicas < replicas-*rollingUpdate.Partition {
+			return false, fmt.Sprintf("%d of %d replicas updated", status.UpdatedReplicas, replicas-*rollingUpdate.Partition)
+		}
+
+		return true, ""
+	}
+
+	if status.UpdateRevision != status.CurrentRevision {
+		return false, fmt.Sprintf("%d of %d replicas updated", status.UpdatedReplicas, replicas)
+	}
+
+	return true, ""
+}
@@ -0,0 +1,174 @@
+package readiness
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestDeploymentRolloutStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		generation int64
+		replicas   *int32
+		status     appsv1.DeploymentStatus
+		wantReady  bool
+		wantReason string
+	}{
+		{
+			name:       "complete",
+			generation: 2,
+			replicas:   int32Ptr(2),
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2,
+				AvailableReplicas: 2},
+			wantReady: true,
+		},
+		{
+			name:       "default replicas",
+			generation: 1,
+			status: appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1,
+				AvailableReplicas: 1},
+			wantReady: true,
+		},
+		{
+			name:       "new generation not observed",
+			generation: 3,
+			replicas:   int32Ptr(1),
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1,
+				AvailableReplicas: 1},
+			wantReason: "waiting for rollout to be observed",
+		},
+		{
+			name:       "replicas not updated",
+			generation: 2,
+			replicas:   int32Ptr(3),
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1,
+				AvailableReplicas: 3},
+			wantReason: "1 of 3 replicas updated",
+		},
+		{
+			name:       "old replicas pending termination",
+			generation: 2,
+			replicas:   int32Ptr(1),
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1,
+				AvailableReplicas: 1},
+			wantReason: "1 old replicas pending termination",
+		},
+		{
+			name:       "updated replicas not available",
+			generation: 2,
+			replicas:   int32Ptr(2),
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2,
+				AvailableReplicas: 1},
+			wantReason: "1 of 2 updated replicas available",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: tc.generation},
+				Spec:       appsv1.DeploymentSpec{Replicas: tc.replicas},
+				Status:     tc.status,
+			}
+
+			ready, reason := DeploymentRolloutStatus(deployment)
+			if ready != tc.wantReady || reason != tc.wantReason {
+				t.Errorf("expected %v %q, got %v %q", tc.wantReady, tc.wantReason, ready, reason)
+			}
+		})
+	}
+}
+
+func TestStatefulSetRolloutStatus(t *testing.T) {
+	rollingUpdate := appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType}
+	partitioned := appsv1.StatefulSetUpdateStrategy{
+		Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: int32Ptr(1)},
+	}
+	onDelete := appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+
+	tests := []struct {
+		name       string
+		generation int64
+		strategy   appsv1.StatefulSetUpdateStrategy
+		status     appsv1.StatefulSetStatus
+		wantReady  bool
+		wantReason string
+	}{
+		{
+			name:       "complete",
+			generation: 1,
+			strategy:   rollingUpdate,
+			status: appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, UpdatedReplicas: 3,
+				CurrentRevision: "redis-1", UpdateRevision: "redis-1"},
+			wantReady: true,
+		},
+		{
+			name:       "new generation not observed",
+			generation: 2,
+			strategy:   rollingUpdate,
+			status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3},
+			wantReason: "waiting for rollout to be observed",
+		},
+		{
+			name:       "replicas not ready",
+			generation: 1,
+			strategy:   rollingUpdate,
+			status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 2},
+			wantReason: "2 of 3 replicas ready",
+		},
+		{
+			name:       "revision not rolled out",
+			generation: 2,
+			strategy:   rollingUpdate,
+			status: appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 1,
+				CurrentRevision: "redis-1", UpdateRevision: "redis-2"},
+			wantReason: "1 of 3 replicas updated",
+		},
+		{
+			name:       "partition rolled out",
+			generation: 2,
+			strategy:   partitioned,
+			status: appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 2,
+				CurrentRevision: "redis-1", UpdateRevision: "redis-2"},
+			wantReady: true,
+		},
+		{
+			name:       "partition not rolled out",
+			generation: 2,
+			strategy:   partitioned,
+			status: appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 1,
+				CurrentRevision: "redis-1", UpdateRevision: "redis-2"},
+			wantReason: "1 of 2 replicas updated",
+		},
+		{
+			name:       "on delete",
+			generation: 2,
+			strategy:   onDelete,
+			status: appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3,
+				CurrentRevision: "redis-1", UpdateRevision: "redis-2"},
+			wantReady: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: tc.generation},
+				Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3), UpdateStrategy: tc.strategy},
+				Status:     tc.status,
+			}
+
+			ready, reason := StatefulSetRolloutStatus(sts)
+			if ready != tc.wantReady || reason != tc.wantReason {
+				t.Errorf("expected %v %q, got %v %q", tc.wantReady, tc.wantReason, ready, reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,398 @@
+package readiness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// progressInterval is the interval at which the progress summary is repeated, even if it did not change.
+	progressInterval = 30 * time.Second
+	// crashLoopRestartThreshold is the number of restarts after which a container in CrashLoopBackOff is
+	// considered failed, so that a container that crashes while its dependencies start up is not.
+	crashLoopRestartThreshold = 3
+	// revisionAnnotation holds the revision of a Deployment and of its ReplicaSets.
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+)
+
+// waiter evaluates readiness targets against the informer caches of a single namespace.
+type waiter struct {
+	namespace    string
+	pods         corelisters.PodLister
+	deployments  appslisters.DeploymentLister
+	replicaSets  appslisters.ReplicaSetLister
+	statefulSets appslisters.StatefulSetLister
+}
+
+// targetStatus is the readiness of a single target.
+type targetStatus struct {
+	target Target
+	ready  bool
+	// details describes why the target is not ready.
+	details []string
+	// failure is set if a Pod of the target failed in a way that does not recover without intervention.
+	failure error
+}
+
+// WaitForTargets watches the workloads of the given targets in namespace with clientset until all of them
+// are ready. It fails early if a Pod of the current revision of a target cannot start, e.g. because it keeps
+// crashing or cannot pull its image, and fails with a summary of the targets that are not ready once ctx is
+// done.
+func WaitForTargets(ctx context.Context, clientset kubernetes.Interface, namespace string,
+	targets []Target) error {
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { notify() },
+		UpdateFunc: func(interface{}, interface{}) { notify() },
+		DeleteFunc: func(interface{}) { notify() },
+	}
+
+	// Pods are always watched to detect failures, Deployments and StatefulSets only if they are targeted,
+	// so that no permissions are needed for kinds that are not used. ReplicaSets are watched along with
+	// Deployments to tell the Pods of the current rollout from the ones being replaced.
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, informers.WithNamespace(namespace))
+	w := &waiter{namespace: namespace}
+
+	w.pods = factory.Core().V1().Pods().Lister()
+	factory.Core().V1().Pods().Informer().AddEventHandler(handler)
+
+	for _, target := range targets {
+		switch {
+		case target.Kind == KindDeployment && w.deployments == nil:
+			w.deployments = factory.Apps().V1().Deployments().Lister()
+			factory.Apps().V1().Deployments().Informer().AddEventHandler(handler)
+			w.replicaSets = factory.Apps().V1().ReplicaSets().Lister()
+			factory.Apps().V1().ReplicaSets().Informer().AddEventHandler(handler)
+		case target.Kind == KindStatefulSet && w.statefulSets == nil:
+			w.statefulSets = factory.Apps().V1().StatefulSets().Lister()
+			factory.Apps().V1().StatefulSets().Informer().AddEventHandler(handler)
+		}
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+
+	for informer, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("failed to sync %v informer before readiness check timed out", informer)
+		}
+	}
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	var lastSummary string
+	for {
+		statuses, err := w.evaluate(targets)
+		if err != nil {
+			return err
+		}
+
+		ready := 0
+		for _, status := range statuses {
+			if status.failure != nil {
+				return status.failure
+			}
+			if status.ready {
+				ready++
+			}
+		}
+
+		if ready == len(statuses) {
+			fmt.Printf("All %d readiness targets are ready\n", len(statuses))
+			return nil
+		}
+
+		summary := summarise(statuses, ready)
+		if summary != lastSummary {
+			fmt.Printf("Waiting for readiness targets, %v\n", summary)
+			lastSummary = summary
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("readiness check timed out, %v", summary)
+		case <-changed:
+		case <-ticker.C:
+			fmt.Printf("Waiting for readiness targets, %v\n", summary)
+		}
+	}
+}
+
+func (w *waiter) evaluate(targets []Target) ([]targetStatus, error) {
+	statuses := make([]targetStatus, 0, len(targets))
+	for _, target := range targets {
+		status, err := w.evaluateTarget(target)
+		if err != nil {
+			return nil, err
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func (w *waiter) evaluateTarget(target Target) (targetStatus, error) {
+	status := targetStatus{target: target, ready: true}
+
+	selector := labels.Everything()
+	if target.Selector != "" {
+		var err error
+		if selector, err = labels.Parse(target.Selector); err != nil {
+			return status, fmt.Errorf("invalid label selector of readiness target %v, err: %v", target, err)
+		}
+	}
+
+	// podSelectors select the Pods checked for failures, i.e. the Pods of the current revision of the
+	// targeted workloads.
+	var podSelectors []labels.Selector
+	found := 0
+
+	switch target.Kind {
+	case KindPod:
+		pods, err := w.pods.Pods(w.namespace).List(selector)
+		if err != nil {
+			return status, err
+		}
+
+		for _, pod := range pods {
+			if target.Name != "" && pod.Name != target.Name {
+				continue
+			}
+
+			found++
+			if ready, reason := podStatus(pod); !ready {
+				status.ready = false
+				status.details = append(status.details, fmt.Sprintf("pod/%v %v", pod.Name, reason))
+			}
+			if status.failure == nil {
+				status.failure = podFailure(pod)
+			}
+		}
+	case KindDeployment:
+		deployments, err := w.listDeployments(target, selector)
+		if err != nil {
+			return status, err
+		}
+
+		for _, deployment := range deployments {
+			found++
+			if ready, reason := DeploymentRolloutStatus(deployment); !ready {
+				status.ready = false
+				status.details = append(status.details, fmt.Sprintf("deployment/%v %v", deployment.Name, reason))
+			}
+			s, err := w.currentReplicaSetSelector(deployment)
+			if err != nil {
+				return status, err
+			}
+			if s != nil {
+				podSelectors = append(podSelectors, s)
+			}
+		}
+	case KindStatefulSet:
+		statefulSets, err := w.listStatefulSets(target, selector)
+		if err != nil {
+			return status, err
+		}
+
+		for _, sts := range statefulSets {
+			found++
+			if ready, reason := StatefulSetRolloutStatus(sts); !ready {
+				status.ready = false
+				status.details = append(status.details, fmt.Sprintf("statefulset/%v %v", sts.Name, reason))
+			}
+			if s := currentRevisionSelector(sts.Spec.Selector, appsv1.ControllerRevisionHashLabelKey,
+				sts.Status.UpdateRevision); s != nil {
+				podSelectors = append(podSelectors, s)
+			}
+		}
+	default:
+		return status, fmt.Errorf("unsupported readiness target kind %v", target.Kind)
+	}
+
+	if found == 0 {
+		if target.Optional {
+			return status, nil
+		}
+
+		status.ready = false
+		status.details = []string{"not found"}
+		return status, nil
+	}
+
+	if status.ready || status.failure != nil {
+		return status, nil
+	}
+
+	for _, podSelector := range podSelectors {
+		pods, err := w.pods.Pods(w.namespace).List(podSelector)
+		if err != nil {
+			return status, err
+		}
+
+		for _, pod := range pods {
+			if status.failure = podFailure(pod); status.failure != nil {
+				return status, nil
+			}
+		}
+	}
+
+	return status, nil
+}
+
+// currentReplicaSetSelector returns a selector of the Pods of the ReplicaSet of the current revision of the
+// given Deployment, or nil if that ReplicaSet has not been created yet.
+func (w *waiter) currentReplicaSetSelector(deployment *appsv1.Deployment) (labels.Selector, error) {
+	replicaSets, err := w.replicaSets.ReplicaSets(w.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	revision := deployment.Annotations[revisionAnnotation]
+	for _, rs := range replicaSets {
+		owner := metav1.GetControllerOf(rs)
+		if owner == nil || owner.UID != deployment.UID || rs.Annotations[revisionAnnotation] != revision {
+			continue
+		}
+
+		return currentRevisionSelector(deployment.Spec.Selector, appsv1.DefaultDeploymentUniqueLabelKey,
+			rs.Labels[appsv1.DefaultDeploymentUniqueLabelKey]), nil
+	}
+
+	return nil, nil
+}
+
+// currentRevisionSelector narrows the given workload selector down to the Pods whose label key has the
+// given revision hash, or returns nil if the revision is not known yet.
+func currentRevisionSelector(selector *metav1.LabelSelector, key, revision string) labels.Selector {
+	if revision == "" {
+		return nil
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil
+	}
+
+	req, err := labels.NewRequirement(key, selection.Equals, []string{revision})
+	if err != nil {
+		return nil
+	}
+
+	return s.Add(*req)
+}
+
+func (w *waiter) listDeployments(target Target, selector labels.Selector) ([]*appsv1.Deployment, error) {
+	if target.Name == "" {
+		return w.deployments.Deployments(w.namespace).List(selector)
+	}
+
+	deployment, err := w.deployments.Deployments(w.namespace).Get(target.Name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return []*appsv1.Deployment{deployment}, nil
+}
+
+func (w *waiter) listStatefulSets(target Target, selector labels.Selector) ([]*appsv1.StatefulSet, error) {
+	if target.Name == "" {
+		return w.statefulSets.StatefulSets(w.namespace).List(selector)
+	}
+
+	sts, err := w.statefulSets.StatefulSets(w.namespace).Get(target.Name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return []*appsv1.StatefulSet{sts}, nil
+}
+
+// fatalWaitingReasons are the reasons of waiting containers that do not recover without intervention.
+// ErrImagePull is left out, since the kubelet retries a failed pull before backing off, and
+// CrashLoopBackOff is only fatal after crashLoopRestartThreshold restarts.
+var fatalWaitingReasons = map[string]bool{
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// podFailure returns an error describing why a container of the given Pod cannot start, or nil if none of
+// its containers is stuck.
+func podFailure(pod *v1.Pod) error {
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		waiting := cs.State.Waiting
+		if waiting == nil {
+			continue
+		}
+
+		crashLooping := waiting.Reason == "CrashLoopBackOff" && cs.RestartCount >= crashLoopRestartThreshold
+		if !crashLooping && !fatalWaitingReasons[waiting.Reason] {
+			continue
+		}
+
+		msg := fmt.Sprintf("container %v of pod/%v is in %v", cs.Name, pod.Name, waiting.Reason)
+		if crashLooping {
+			msg += fmt.Sprintf(" after %d restarts", cs.RestartCount)
+		}
+		if waiting.Message != "" {
+			msg += fmt.Sprintf(" (%v)", waiting.Message)
+		}
+
+		if terminated := cs.LastTerminationState.Terminated; terminated != nil {
+			msg += fmt.Sprintf(", last terminated with reason %v and exit code %d",
+				terminated.Reason, terminated.ExitCode)
+			if terminated.Message != "" {
+				msg += fmt.Sprintf(": %v", strings.TrimSpace(terminated.Message))
+			}
+		}
+
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// summarise returns a compact, single line summary of the targets that are not ready.
+func summarise(statuses []targetStatus, ready int) string {
+	var notReady []string
+	for _, status := range statuses {
+		if !status.ready {
+			notReady = append(notReady, fmt.Sprintf("%v (%v)", status.target, strings.Join(status.details, "; ")))
+		}
+	}
+
+	return fmt.Sprintf("%d of %d ready, not ready: %v",
+		ready, len(statuses), strings.Join(notReady, ", "))
+}
@@ -0,0 +1,344 @@
+package readiness
+
+import (
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const testNamespace = "tyk"
+
+// waitingPod returns a Pod with the given labels whose single container is waiting for reason after the
+// given number of restarts.
+func waitingPod(name string, podLabels map[string]string, reason string, restarts int32) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: podLabels},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app"}}},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+			ContainerStatuses: []v1.ContainerStatus{{
+				Name:         "app",
+				RestartCount: restarts,
+				State:        v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: reason}},
+			}},
+		},
+	}
+}
+
+func TestPodFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		pod     *v1.Pod
+		wantErr string
+	}{
+		{name: "running", pod: &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "dashboard"}}},
+		{name: "container creating", pod: waitingPod("dashboard", nil, "ContainerCreating", 0)},
+		{name: "image pull retried", pod: waitingPod("dashboard", nil, "ErrImagePull", 0)},
+		{name: "crash loop below threshold", pod: waitingPod("dashboard", nil, "CrashLoopBackOff", 2)},
+		{
+			name:    "crash loop at threshold",
+			pod:     waitingPod("dashboard", nil, "CrashLoopBackOff", 3),
+			wantErr: "container app of pod/dashboard is in CrashLoopBackOff after 3 restarts",
+		},
+		{
+			name:    "image pull back-off",
+			pod:     waitingPod("dashboard", nil, "ImagePullBackOff", 0),
+			wantErr: "container app of pod/dashboard is in ImagePullBackOff",
+		},
+		{
+			name: "init container",
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "dashboard"},
+				Status: v1.PodStatus{InitContainerStatuses: []v1.ContainerStatus{{
+					Name: "init",
+					State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{
+						Reason:  "CreateContainerConfigError",
+						Message: `secret "tyk" not found`,
+					}},
+				}}},
+			},
+			wantErr: `container init of pod/dashboard is in CreateContainerConfigError (secret "tyk" not found)`,
+		},
+		{
+			name: "last termination",
+			pod: func() *v1.Pod {
+				pod := waitingPod("dashboard", nil, "CrashLoopBackOff", 4)
+				pod.Status.ContainerStatuses[0].LastTerminationState.Terminated = &v1.ContainerStateTerminated{
+					Reason:   "Error",
+					ExitCode: 1,
+					Message:  "cannot connect to redis\n",
+				}
+				return pod
+			}(),
+			wantErr: "container app of pod/dashboard is in CrashLoopBackOff after 4 restarts, last terminated " +
+				"with reason Error and exit code 1: cannot connect to redis",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := podFailure(tc.pod)
+			if tc.wantErr == "" && err != nil || tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Errorf("expected error %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCurrentRevisionSelector(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "dashboard"}}
+
+	tests := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		revision string
+		want     string
+	}{
+		{name: "revision", selector: selector, revision: "abc", want: "app=dashboard,pod-template-hash=abc"},
+		{name: "unknown revision", selector: selector},
+		{
+			name:     "invalid selector",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "-"}},
+			revision: "abc",
+		},
+		{name: "invalid revision", selector: selector, revision: "a b"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := currentRevisionSelector(tc.selector, appsv1.DefaultDeploymentUniqueLabelKey, tc.revision)
+			switch {
+			case tc.want == "" && s != nil:
+				t.Errorf("expected no selector, got %v", s)
+			case tc.want != "" && (s == nil || s.String() != tc.want):
+				t.Errorf("expected selector %v, got %v", tc.want, s)
+			}
+		})
+	}
+}
+
+// newTestWaiter returns a waiter whose listers hold the given objects.
+func newTestWaiter(t *testing.T, objects ...runtime.Object) *waiter {
+	t.Helper()
+
+	indexers := map[string]cache.Indexer{}
+	indexer := func(kind string) cache.Indexer {
+		if indexers[kind] == nil {
+			indexers[kind] = cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+				cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		}
+		return indexers[kind]
+	}
+
+	for _, obj := range objects {
+		var kind string
+		switch obj.(type) {
+		case *v1.Pod:
+			kind = "pods"
+		case *appsv1.Deployment:
+			kind = "deployments"
+		case *appsv1.ReplicaSet:
+			kind = "replicasets"
+		case *appsv1.StatefulSet:
+			kind = "statefulsets"
+		}
+		if err := indexer(kind).Add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return &waiter{
+		namespace:    testNamespace,
+		pods:         corelisters.NewPodLister(indexer("pods")),
+		deployments:  appslisters.NewDeploymentLister(indexer("deployments")),
+		replicaSets:  appslisters.NewReplicaSetLister(indexer("replicasets")),
+		statefulSets: appslisters.NewStatefulSetLister(indexer("statefulsets")),
+	}
+}
+
+// rollingDeployment returns the dashboard Deployment at revision 2, whose rollout is not complete, along
+// with the ReplicaSets of revisions 1 and 2.
+func rollingDeployment() (*appsv1.Deployment, *appsv1.ReplicaSet, *appsv1.ReplicaSet) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "dashboard",
+			Namespace:   testNamespace,
+			UID:         types.UID("dashboard-uid"),
+			Generation:  2,
+			Annotations: map[string]string{revisionAnnotation: "2"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(1),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "dashboard"}},
+		},
+		Status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1,
+			AvailableReplicas: 1},
+	}
+
+	replicaSet := func(revision, hash string) *appsv1.ReplicaSet {
+		controller := true
+		return &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:        "dashboard-" + hash,
+			Namespace:   testNamespace,
+			Labels:      map[string]string{"app": "dashboard", appsv1.DefaultDeploymentUniqueLabelKey: hash},
+			Annotations: map[string]string{revisionAnnotation: revision},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       deployment.Name,
+				UID:        deployment.UID,
+				Controller: &controller,
+			}},
+		}}
+	}
+
+	return deployment, replicaSet("1", "old"), replicaSet("2", "new")
+}
+
+func TestEvaluateDeploymentTarget(t *testing.T) {
+	deployment, oldRS, newRS := rollingDeployment()
+	oldLabels := map[string]string{"app": "dashboard", appsv1.DefaultDeploymentUniqueLabelKey: "old"}
+	newLabels := map[string]string{"app": "dashboard", appsv1.DefaultDeploymentUniqueLabelKey: "new"}
+
+	tests := []struct {
+		name        string
+		objects     []runtime.Object
+		wantDetails string
+		wantFailure string
+	}{
+		{
+			name:        "old revision crashing",
+			objects:     []runtime.Object{waitingPod("dashboard-old", oldLabels, "CrashLoopBackOff", 10)},
+			wantDetails: "[deployment/dashboard 1 old replicas pending termination]",
+		},
+		{
+			name:        "current revision crashing below threshold",
+			objects:     []runtime.Object{waitingPod("dashboard-new", newLabels, "CrashLoopBackOff", 2)},
+			wantDetails: "[deployment/dashboard 1 old replicas pending termination]",
+		},
+		{
+			name:        "current revision crashing",
+			objects:     []runtime.Object{waitingPod("dashboard-new", newLabels, "CrashLoopBackOff", 3)},
+			wantFailure: "container app of pod/dashboard-new is in CrashLoopBackOff after 3 restarts",
+		},
+		{
+			name:        "current revision image pull back-off",
+			objects:     []runtime.Object{waitingPod("dashboard-new", newLabels, "ImagePullBackOff", 0)},
+			wantFailure: "container app of pod/dashboard-new is in ImagePullBackOff",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := newTestWaiter(t, append([]runtime.Object{deployment, oldRS, newRS}, tc.objects...)...)
+
+			status, err := w.evaluateTarget(Target{Kind: KindDeployment, Name: "dashboard"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if status.ready {
+				t.Error("expected the deployment not to be ready")
+			}
+			if tc.wantDetails != "" && fmt.Sprint(status.details) != tc.wantDetails {
+				t.Errorf("expected details %v, got %v", tc.wantDetails, status.details)
+			}
+			if tc.wantFailure == "" && status.failure != nil ||
+				tc.wantFailure != "" && (status.failure == nil || status.failure.Error() != tc.wantFailure) {
+				t.Errorf("expected failure %q, got %v", tc.wantFailure, status.failure)
+			}
+		})
+	}
+}
+
+func TestEvaluateStatefulSetTarget(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: testNamespace, Generation: 2},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:       int32Ptr(1),
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "redis"}},
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+		},
+		Status: appsv1.StatefulSetStatus{ObservedGeneration: 2, CurrentRevision: "redis-1",
+			UpdateRevision: "redis-2"},
+	}
+	podLabels := func(revision string) map[string]string {
+		return map[string]string{"app": "redis", appsv1.ControllerRevisionHashLabelKey: revision}
+	}
+
+	tests := []struct {
+		name        string
+		pod         *v1.Pod
+		wantFailure string
+	}{
+		{name: "old revision crashing", pod: waitingPod("redis-0", podLabels("redis-1"), "CrashLoopBackOff", 5)},
+		{
+			name:        "update revision crashing",
+			pod:         waitingPod("redis-0", podLabels("redis-2"), "CrashLoopBackOff", 5),
+			wantFailure: "container app of pod/redis-0 is in CrashLoopBackOff after 5 restarts",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, err := newTestWaiter(t, sts, tc.pod).evaluateTarget(Target{Kind: KindStatefulSet, Name: "redis"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if status.ready {
+				t.Error("expected the statefulset not to be ready")
+			}
+			if tc.wantFailure == "" && status.failure != nil ||
+				tc.wantFailure != "" && (status.failure == nil || status.failure.Error() != tc.wantFailure) {
+				t.Errorf("expected failure %q, got %v", tc.wantFailure, status.failure)
+			}
+		})
+	}
+}
+
+func TestEvaluatePodTarget(t *testing.T) {
+	podLabels := map[string]string{"app": "gateway"}
+	w := newTestWaiter(t,
+		waitingPod("gateway-a", podLabels, "ContainerCreating", 0),
+		waitingPod("gateway-b", podLabels, "ImagePullBackOff", 0),
+	)
+
+	status, err := w.evaluateTarget(Target{Kind: KindPod, Selector: labels.SelectorFromSet(podLabels).String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.ready || len(status.details) != 2 {
+		t.Errorf("expected both pods not to be ready, got %v", status.details)
+	}
+	wantFailure := "container app of pod/gateway-b is in ImagePullBackOff"
+	if status.failure == nil || status.failure.Error() != wantFailure {
+		t.Errorf("expected failure %q, got %v", wantFailure, status.failure)
+	}
+
+	status, err = w.evaluateTarget(Target{Kind: KindPod, Selector: "app=missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.ready || fmt.Sprint(status.details) != "[not found]" {
+		t.Errorf("expected the target not to be found, got %v", status.details)
+	}
+
+	status, err = w.evaluateTarget(Target{Kind: KindPod, Selector: "app=missing", Optional: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.ready {
+		t.Errorf("expected the optional target to be ready, got %v", status.details)
+	}
+}
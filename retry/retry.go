@@ -0,0 +1,146 @@
+// Package retry implements the retry policy applied to Tyk Dashboard and Kubernetes API calls.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Policy configures how failed calls are retried.
+type Policy struct {
+	// MaxAttempts is the maximum number of attempts of a call, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. It is multiplied by Multiplier for every further
+	// retry, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter is the fraction by which each delay is randomly increased or decreased.
+	Jitter float64
+	// RetryableStatusCodes are the HTTP status codes of responses that are retried.
+	RetryableStatusCodes []int
+	// Timeout limits each attempt of a call. Zero means no limit.
+	Timeout time.Duration
+}
+
+// DefaultPolicy returns the policy used unless configured otherwise.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:    5,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		Timeout: 30 * time.Second,
+	}
+}
+
+// Validate reports whether the policy is usable.
+func (p Policy) Validate() error {
+	switch {
+	case p.MaxAttempts < 1:
+		return fmt.Errorf("max attempts must be at least 1, got %d", p.MaxAttempts)
+	case p.InitialBackoff < 0 || p.MaxBackoff < 0 || p.Timeout < 0:
+		return errors.New("backoff and timeout durations must not be negative")
+	case p.Multiplier < 1:
+		return fmt.Errorf("backoff multiplier must be at least 1, got %v", p.Multiplier)
+	case p.Jitter < 0 || p.Jitter > 1:
+		return fmt.Errorf("jitter must be between 0 and 1, got %v", p.Jitter)
+	}
+
+	return nil
+}
+
+// Backoff returns the delay before the given retry, starting at 1 for the first retry.
+func (p Policy) Backoff(retry int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		backoff *= p.Multiplier
+		if backoff >= float64(p.MaxBackoff) {
+			backoff = float64(p.MaxBackoff)
+			break
+		}
+	}
+
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(backoff)
+}
+
+// IsRetryableStatus reports whether responses with the given status code are retried.
+func (p Policy) IsRetryableStatus(code int) bool {
+	for _, c := range p.RetryableStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Do calls fn until it succeeds, returns an error that is not retryable, the attempts are exhausted or ctx
+// is done. Each attempt is limited by the policy's Timeout. The context passed to fn is marked with
+// WithoutRetry, so that the requests of each attempt are not retried again by Transport.
+func (p Policy) Do(ctx context.Context, retryable func(error) bool, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = p.attempt(ctx, fn)
+		if err == nil || !retryable(err) || attempt >= p.MaxAttempts {
+			return err
+		}
+
+		if err = Sleep(ctx, p.Backoff(attempt), err); err != nil {
+			return err
+		}
+	}
+}
+
+func (p Policy) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx = WithoutRetry(ctx)
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	return fn(ctx)
+}
+
+type withoutRetryKey struct{}
+
+// WithoutRetry returns a copy of ctx whose requests are sent only once by Transport, for calls that are
+// retried by their caller, e.g. by Policy.Do, so that attempts do not multiply across both layers.
+func WithoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutRetryKey{}, true)
+}
+
+func retryDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(withoutRetryKey{}).(bool)
+	return disabled
+}
+
+// Sleep waits for d, or returns an error wrapping lastErr if ctx is done first.
+func Sleep(ctx context.Context, d time.Duration, lastErr error) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%v, last error: %w", ctx.Err(), lastErr)
+	case <-timer.C:
+		return nil
+	}
+}
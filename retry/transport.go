@@ -0,0 +1,148 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Transport is an http.RoundTripper retrying requests according to Policy.
+//
+// Requests with idempotent methods are retried on connection errors and on responses with a retryable
+// status code. Other requests, such as POST, may have been processed by the server even if they failed, so
+// they are only retried if the connection could not be established, or if the server answered with 429 Too
+// Many Requests or 503 Service Unavailable, which indicate that the request was not processed. Watch
+// requests are neither retried nor limited by the policy's Timeout. Requests whose context is marked with
+// WithoutRetry are sent once, limited by the policy's Timeout.
+type Transport struct {
+	Base   http.RoundTripper
+	Policy Policy
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if req.URL.Query().Get("watch") == "true" {
+		return base.RoundTrip(req)
+	}
+
+	// A request whose body cannot be replayed is only sent once.
+	canReplay := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	maxAttempts := t.Policy.MaxAttempts
+	if retryDisabled(req.Context()) {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptReq, cancel, err := t.prepare(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := base.RoundTrip(attemptReq)
+
+		retry := canReplay && attempt < maxAttempts && t.retryable(req, res, err)
+		if !retry {
+			if res != nil {
+				res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+			} else {
+				cancel()
+			}
+
+			return res, err
+		}
+
+		var lastErr error = err
+		if res != nil {
+			lastErr = errors.New(res.Status)
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		cancel()
+
+		if err = Sleep(req.Context(), t.Policy.Backoff(attempt), lastErr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// prepare returns a copy of req for the given attempt, with a fresh body and limited by the policy's
+// Timeout.
+func (t *Transport) prepare(req *http.Request, attempt int) (*http.Request, context.CancelFunc, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.Policy.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.Policy.Timeout)
+	}
+
+	attemptReq := req.Clone(ctx)
+	if attempt > 1 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		attemptReq.Body = body
+	}
+
+	return attemptReq, cancel, nil
+}
+
+func (t *Transport) retryable(req *http.Request, res *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	idempotent := isIdempotent(req.Method)
+
+	if err != nil {
+		return idempotent || IsDialError(err)
+	}
+
+	if !t.Policy.IsRetryableStatus(res.StatusCode) {
+		return false
+	}
+
+	return idempotent ||
+		res.StatusCode == http.StatusTooManyRequests ||
+		res.StatusCode == http.StatusServiceUnavailable
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsDialError reports whether err occurred while establishing a connection, in which case the request
+// has not been sent.
+func IsDialError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// cancelOnClose releases the context of an attempt once the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+
+	return err
+}
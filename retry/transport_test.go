@@ -0,0 +1,202 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripperFunc answers requests with the given function.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func testPolicy() Policy {
+	policy := DefaultPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = time.Millisecond
+	policy.Jitter = 0
+
+	return policy
+}
+
+// countingTransport returns a Transport whose base answers every request with status, or with err if it is
+// set, along with the number of requests the base received.
+func countingTransport(status int, err error) (*Transport, *int) {
+	attempts := 0
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if err != nil {
+			return nil, err
+		}
+
+		return &http.Response{StatusCode: status, Status: http.StatusText(status), Body: http.NoBody}, nil
+	})
+
+	return &Transport{Base: base, Policy: testPolicy()}, &attempts
+}
+
+func TestTransportRetries(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}
+
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		status       int
+		err          error
+		wantAttempts int
+	}{
+		{name: "GET on success", method: http.MethodGet, status: http.StatusOK, wantAttempts: 1},
+		{name: "GET on 500", method: http.MethodGet, status: http.StatusInternalServerError, wantAttempts: 5},
+		{name: "GET on 429", method: http.MethodGet, status: http.StatusTooManyRequests, wantAttempts: 5},
+		{name: "GET on 404", method: http.MethodGet, status: http.StatusNotFound, wantAttempts: 1},
+		{name: "GET on read error", method: http.MethodGet, err: readErr, wantAttempts: 5},
+		{name: "PUT on 502", method: http.MethodPut, status: http.StatusBadGateway, wantAttempts: 5},
+		{name: "DELETE on 504", method: http.MethodDelete, status: http.StatusGatewayTimeout, wantAttempts: 5},
+		{name: "POST on 500", method: http.MethodPost, status: http.StatusInternalServerError, wantAttempts: 1},
+		{name: "POST on 503", method: http.MethodPost, status: http.StatusServiceUnavailable, wantAttempts: 5},
+		{name: "POST on 429", method: http.MethodPost, status: http.StatusTooManyRequests, wantAttempts: 5},
+		{name: "POST on dial error", method: http.MethodPost, err: dialErr, wantAttempts: 5},
+		{name: "POST on read error", method: http.MethodPost, err: readErr, wantAttempts: 1},
+		{name: "PATCH on 502", method: http.MethodPatch, status: http.StatusBadGateway, wantAttempts: 1},
+		{
+			name:         "watch on 500",
+			method:       http.MethodGet,
+			url:          "http://example.com/api/v1/pods?watch=true",
+			status:       http.StatusInternalServerError,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			transport, attempts := countingTransport(tc.status, tc.err)
+
+			url := tc.url
+			if url == "" {
+				url = "http://example.com/api/users"
+			}
+
+			req, err := http.NewRequest(tc.method, url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := transport.RoundTrip(req)
+			if res != nil {
+				res.Body.Close()
+			}
+			if (err != nil) != (tc.err != nil) {
+				t.Errorf("unexpected error %v", err)
+			}
+
+			if *attempts != tc.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tc.wantAttempts, *attempts)
+			}
+		})
+	}
+}
+
+func TestTransportReplaysBody(t *testing.T) {
+	var bodies []string
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, string(body))
+
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+	})
+	transport := &Transport{Base: base, Policy: testPolicy()}
+
+	req, err := http.NewRequest(http.MethodPost, "http://example.com/api/users", strings.NewReader(`{"a":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bodies) != 5 {
+		t.Fatalf("expected 5 attempts, got %d", len(bodies))
+	}
+	for _, body := range bodies {
+		if body != `{"a":1}` {
+			t.Errorf("expected every attempt to send the request body, got %q", body)
+		}
+	}
+}
+
+func TestTransportWithoutRetry(t *testing.T) {
+	transport, attempts := countingTransport(http.StatusServiceUnavailable, nil)
+
+	req, err := http.NewRequestWithContext(WithoutRetry(context.Background()), http.MethodGet,
+		"http://example.com/hello", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if *attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", *attempts)
+	}
+}
+
+func TestDoDoesNotMultiplyAttempts(t *testing.T) {
+	transport, attempts := countingTransport(http.StatusServiceUnavailable, nil)
+	client := &http.Client{Transport: transport}
+	policy := testPolicy()
+
+	calls := 0
+	err := policy.Do(context.Background(), func(error) bool { return true }, func(ctx context.Context) error {
+		calls++
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://example.com/api/users", nil)
+		if err != nil {
+			return err
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+
+		return errors.New(res.Status)
+	})
+	if err == nil {
+		t.Fatal("expected the call to fail")
+	}
+
+	if calls != policy.MaxAttempts || *attempts != policy.MaxAttempts {
+		t.Errorf("expected %d calls and requests, got %d calls and %d requests",
+			policy.MaxAttempts, calls, *attempts)
+	}
+}
+
+func TestDoStopsOnNonRetryableError(t *testing.T) {
+	policy := testPolicy()
+
+	calls := 0
+	err := policy.Do(context.Background(), func(error) bool { return false }, func(context.Context) error {
+		calls++
+		return errors.New("bad request")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected a single failed call, got %d calls and error %v", calls, err)
+	}
+}